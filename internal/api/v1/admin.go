@@ -0,0 +1,299 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/monitoring"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/pkg/config"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// smsStreamInterval is how often the SSE endpoint pushes stat frames
+const smsStreamInterval = 2 * time.Second
+
+// AdminHandler handles administrative operations on the SMS job queue and
+// runtime diagnostics
+type AdminHandler struct {
+	jobQueue       *jobs.RedisJobQueue
+	smsJobs        *repositories.SMSJobRepository
+	smsService     *services.SMSService
+	logger         *monitoring.Logger
+	cfg            *config.Config
+	streamInterval time.Duration
+}
+
+func NewAdminHandler(jobQueue *jobs.RedisJobQueue, smsJobs *repositories.SMSJobRepository, smsService *services.SMSService, logger *monitoring.Logger, cfg *config.Config) *AdminHandler {
+	return &AdminHandler{
+		jobQueue:       jobQueue,
+		smsJobs:        smsJobs,
+		smsService:     smsService,
+		logger:         logger,
+		cfg:            cfg,
+		streamInterval: smsStreamInterval,
+	}
+}
+
+// TestSMSRequest is the payload for POST /api/v1/admin/sms/test
+type TestSMSRequest struct {
+	Phone   string `json:"phone" binding:"required,min=10,max=20"`
+	Message string `json:"message" binding:"required,min=1,max=480"`
+}
+
+// TestSMS handles POST /api/v1/admin/sms/test
+//
+// Sends one SMS synchronously, bypassing the queue, and returns the
+// provider's raw response so ops can validate credentials for a new
+// environment.
+func (h *AdminHandler) TestSMS(c *gin.Context) {
+	var req TestSMSRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data", err.Error())
+		return
+	}
+
+	response, err := h.smsService.SendTest(c.Request.Context(), req.Phone, req.Message)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Test SMS dispatched", response)
+}
+
+// GetConfig handles GET /api/v1/admin/config
+//
+// Returns the effective configuration with secrets redacted, so ops can
+// confirm what the process actually loaded across environments.
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	utils.SuccessResponse(c, "Effective configuration", h.cfg.Redacted())
+}
+
+// ListSMSHistory handles GET /api/v1/admin/sms/history?status=&offset=&limit=
+//
+// Lists the durable Postgres record of final job states, which survives
+// Redis key expiry.
+func (h *AdminHandler) ListSMSHistory(c *gin.Context) {
+	status := c.Query("status")
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		utils.BadRequestResponse(c, "Invalid offset parameter", map[string]string{"offset": "must be a non-negative integer"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		utils.BadRequestResponse(c, "Invalid limit parameter", map[string]string{"limit": "must be a positive integer"})
+		return
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	records, total, err := h.smsJobs.List(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "SMS job history retrieved successfully", gin.H{
+		"jobs": records,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  limit,
+			"offset": offset,
+		},
+	})
+}
+
+// StreamSMSStats handles GET /api/v1/admin/sms/stream
+//
+// Pushes job queue statistics as server-sent events every few seconds so
+// dashboards get live updates without polling. The stream ends when the
+// client disconnects.
+func (h *AdminHandler) StreamSMSStats(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	ticker := time.NewTicker(h.streamInterval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := h.jobQueue.GetStats(c.Request.Context())
+		if err == nil {
+			payload, marshalErr := json.Marshal(stats)
+			if marshalErr == nil {
+				fmt.Fprintf(c.Writer, "event: stats\ndata: %s\n\n", payload)
+				c.Writer.Flush()
+			}
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			// Client went away
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetLogLevelRequest is the payload for POST /api/v1/admin/log-level
+type SetLogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// SetLogLevel handles POST /api/v1/admin/log-level
+//
+// Changes the live logger's verbosity without a restart. The level must be
+// one of the known logrus levels (panic, fatal, error, warn, info, debug,
+// trace).
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if err := h.logger.SetLevelByName(req.Level); err != nil {
+		utils.BadRequestResponse(c, "Invalid log level", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Log level updated", gin.H{
+		"level": h.logger.GetLevel().String(),
+	})
+}
+
+// ListSMSJobs handles GET /api/v1/admin/sms/jobs?status=&offset=&limit=
+//
+// Returns a page of jobs in the requested status (pending, retry,
+// processing, completed or failed) with pagination metadata.
+func (h *AdminHandler) ListSMSJobs(c *gin.Context) {
+	status := c.DefaultQuery("status", "pending")
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		utils.BadRequestResponse(c, "Invalid offset parameter", map[string]string{"offset": "must be a non-negative integer"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		utils.BadRequestResponse(c, "Invalid limit parameter", map[string]string{"limit": "must be a positive integer"})
+		return
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	smsJobs, total, err := h.jobQueue.ListByStatus(c.Request.Context(), status, offset, limit)
+	if err != nil {
+		if errors.Is(err, jobs.ErrUnknownJobStatus) {
+			utils.BadRequestResponse(c, "Invalid status parameter", err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to list SMS jobs", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "SMS jobs retrieved successfully", gin.H{
+		"jobs": smsJobs,
+		"pagination": gin.H{
+			"total":  total,
+			"limit":  limit,
+			"offset": offset,
+		},
+	})
+}
+
+// GetSMSJob handles GET /api/v1/admin/sms/jobs/:id
+//
+// Returns the hydrated job plus its current queue status and the stored
+// error message, for inspecting a single failure in detail.
+func (h *AdminHandler) GetSMSJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid job ID", err.Error())
+		return
+	}
+
+	job, status, storedError, err := h.jobQueue.GetJob(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			utils.NotFoundResponse(c, "SMS job not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch SMS job", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "SMS job retrieved successfully", gin.H{
+		"job":    job,
+		"status": status,
+		"error":  storedError,
+	})
+}
+
+// CleanupSMSJobs handles POST /api/v1/admin/sms/cleanup
+//
+// Removes orphaned job-data keys left behind by crashes between pipeline
+// steps, returning the number reaped.
+func (h *AdminHandler) CleanupSMSJobs(c *gin.Context) {
+	reaped, err := h.jobQueue.ReapOrphanedJobData(c.Request.Context())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to reap orphaned job data", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Orphaned SMS job data reaped", gin.H{
+		"reaped": reaped,
+	})
+}
+
+// RetryFailedSMSJobs handles POST /api/v1/admin/sms/retry-failed
+//
+// Moves all failed SMS jobs back to the pending queue with reset attempt
+// counters. Supports optional `from`/`to` (RFC3339) and `error_contains`
+// query parameters to narrow which jobs are requeued.
+func (h *AdminHandler) RetryFailedSMSJobs(c *gin.Context) {
+	var filter jobs.RequeueFailedFilter
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid 'from' timestamp", err.Error())
+			return
+		}
+		filter.From = from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid 'to' timestamp", err.Error())
+			return
+		}
+		filter.To = to
+	}
+	filter.ErrorContains = c.Query("error_contains")
+
+	requeued, err := h.jobQueue.RequeueFailed(c.Request.Context(), filter)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to requeue failed SMS jobs", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Failed SMS jobs requeued", gin.H{
+		"requeued": requeued,
+	})
+}
@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend/internal/jobs"
+	"backend/internal/monitoring"
+	"backend/internal/services"
+)
+
+func logLevelRouter(logger *monitoring.Logger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewAdminHandler(nil, nil, nil, logger, nil)
+	router.POST("/admin/log-level", handler.SetLogLevel)
+	return router
+}
+
+func TestSetLogLevel_ChangesLiveLogger(t *testing.T) {
+	logger := monitoring.NewLogger("development")
+	router := logLevelRouter(logger)
+
+	assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/admin/log-level", strings.NewReader(`{"level":"warn"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, logrus.WarnLevel, logger.GetLevel())
+
+	// Subsequent log calls below the new level are filtered out
+	assert.False(t, logger.IsLevelEnabled(logrus.InfoLevel))
+	assert.True(t, logger.IsLevelEnabled(logrus.WarnLevel))
+}
+
+func TestStreamSMSStats_PushesFrames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	handler := NewAdminHandler(jobs.NewRedisJobQueue(client), nil, nil, monitoring.NewLogger("test"), nil)
+	handler.streamInterval = 20 * time.Millisecond
+
+	router := gin.New()
+	router.GET("/admin/sms/stream", handler.StreamSMSStats)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/admin/sms/stream", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Read until two complete SSE frames have arrived
+	reader := bufio.NewReader(resp.Body)
+	frames := 0
+	for frames < 2 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "data: ") {
+			var stats map[string]int64
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &stats))
+			assert.Contains(t, stats, "pending")
+			frames++
+		}
+	}
+
+	// Cancelling the request ends the stream server-side
+	cancel()
+}
+
+func TestSetLogLevel_RejectsUnknownLevel(t *testing.T) {
+	logger := monitoring.NewLogger("development")
+	router := logLevelRouter(logger)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/admin/log-level", strings.NewReader(`{"level":"loud"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+}
+
+func TestSMSDeliveryReport_TokenGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	smsService := services.NewSMSService(&services.SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, nil)
+	router := gin.New()
+	router.POST("/webhooks/sms/delivery", NewWebhookHandler(smsService, "hook-secret").SMSDeliveryReport)
+
+	post := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/sms/delivery",
+			strings.NewReader("id="+uuid.NewString()+"&status=Delivered"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if token != "" {
+			req.Header.Set("X-Webhook-Token", token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("").Code)
+	assert.Equal(t, http.StatusUnauthorized, post("wrong").Code)
+	assert.Equal(t, http.StatusOK, post("hook-secret").Code)
+}
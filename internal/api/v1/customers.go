@@ -1,61 +1,86 @@
 package v1
 
 import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"backend/internal/repositories"
+	"backend/internal/services"
 	"backend/pkg/models"
 	"backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
+// protobufContentType is the media type list endpoints accept for
+// protobuf-encoded responses (JSON remains the default)
+const protobufContentType = "application/x-protobuf"
+
+// flatContentType is the media type requesting the bare array instead of
+// the {success,message,data} envelope on list endpoints
+const flatContentType = "application/vnd.savannah.flat+json"
+
+// wantsFlatResponse reports whether the client asked for the unwrapped
+// array (?format=flat or the flat media type); the envelope stays the
+// default
+func wantsFlatResponse(c *gin.Context) bool {
+	return c.Query("format") == "flat" || c.GetHeader("Accept") == flatContentType
+}
+
 type CustomerHandler struct {
-	customerRepo *repositories.CustomerRepository
+	customerService *services.CustomerService
 }
 
-func NewCustomerHandler(customerRepo *repositories.CustomerRepository) *CustomerHandler {
+func NewCustomerHandler(customerService *services.CustomerService) *CustomerHandler {
 	return &CustomerHandler{
-		customerRepo: customerRepo,
+		customerService: customerService,
 	}
 }
 
 // CreateCustomer handles POST /v1/customers
 func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
-	var req models.CreateCustomerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request data", err.Error())
+	if !utils.RequireBody(c) {
 		return
 	}
 
-	// Check if customer code already exists
-	exists, err := h.customerRepo.Exists(req.Code)
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to check customer existence", err.Error())
+	var req models.CreateCustomerRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data", err.Error())
 		return
 	}
-	if exists {
-		utils.BadRequestResponse(c, "Customer code already exists", map[string]string{"code": "already taken"})
-		return
+
+	// Default to English messaging unless the client specifies a locale
+	locale := req.Locale
+	if locale == "" {
+		locale = "en"
 	}
 
-	// Create customer
 	customer := &models.Customer{
 		Code:      req.Code,
 		Name:      req.Name,
 		Phone:     req.Phone,
 		Email:     req.Email,
+		Locale:    locale,
 		Version:   1,
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := h.customerRepo.Create(customer); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create customer", err.Error())
+	customer, err := h.customerService.CreateCustomer(c.Request.Context(), customer)
+	if err != nil {
+		// Concurrent requests with the same code race on the unique
+		// constraint; the loser should get a 409, not a 500.
+		if repositories.IsUniqueViolation(err) {
+			utils.ErrorResponse(c, http.StatusConflict, "Customer code already exists", map[string]string{"code": "customer.code_taken"})
+			return
+		}
+		utils.RespondError(c, err)
 		return
 	}
 
@@ -75,53 +100,175 @@ func (h *CustomerHandler) GetCustomer(c *gin.Context) {
 		return
 	}
 
-	customer, err := h.customerRepo.GetByID(id)
+	customer, err := h.customerService.GetCustomerByID(c.Request.Context(), id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Customer not found")
-		} else {
-			utils.InternalServerErrorResponse(c, "Failed to retrieve customer", err.Error())
-		}
+		utils.RespondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, "Customer retrieved successfully", customer)
 }
 
+// HeadCustomer handles HEAD /v1/customers/:id
+//
+// Reports existence with 200/404 and no body, using a count instead of
+// fetching the row.
+func (h *CustomerHandler) HeadCustomer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	exists, err := h.customerService.CustomerExists(c.Request.Context(), id)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if exists {
+		c.Status(http.StatusOK)
+	} else {
+		c.Status(http.StatusNotFound)
+	}
+}
+
 // ListCustomers handles GET /v1/customers with query parameters
 func (h *CustomerHandler) ListCustomers(c *gin.Context) {
 	var query models.ListCustomersQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
-		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		utils.RespondQueryValidationError(c, err)
 		return
 	}
 
-	// Set default pagination values
-	if query.Limit == 0 {
-		query.Limit = 20
+	query.Normalize()
+
+	customers, total, hasMore, err := h.customerService.SearchCustomers(c.Request.Context(), &query)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
 	}
-	if query.Limit > 100 {
-		query.Limit = 100
+
+	// High-throughput internal consumers can opt into protobuf encoding
+	if c.GetHeader("Accept") == protobufContentType {
+		c.Data(http.StatusOK, protobufContentType, models.MarshalCustomerListProto(customers, total))
+		return
 	}
 
-	customers, total, err := h.customerRepo.List(&query)
-	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to retrieve customers", err.Error())
+	// Some consumers want the bare array without the envelope
+	if wantsFlatResponse(c) {
+		c.JSON(http.StatusOK, customers)
 		return
 	}
 
+	pagination := gin.H{
+		"limit":  query.Limit,
+		"offset": query.Offset,
+	}
+	if query.Count {
+		pagination["total"] = total
+	} else {
+		pagination["has_more"] = hasMore
+	}
+
 	response := gin.H{
-		"customers": customers,
-		"pagination": gin.H{
-			"total":  total,
-			"limit":  query.Limit,
-			"offset": query.Offset,
-		},
+		"customers":  customers,
+		"pagination": pagination,
 	}
 
 	utils.SuccessResponse(c, "Customers retrieved successfully", response)
 }
 
+// ExportCustomers handles GET /v1/customers/export?cursor=&limit=
+//
+// Streams the customer list as CSV in resumable chunks: each response
+// carries an X-Next-Cursor header the client passes back to fetch the next
+// chunk, so very large exports never hit the request timeout.
+func (h *CustomerHandler) ExportCustomers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 {
+		utils.BadRequestResponse(c, "Invalid limit parameter", map[string]string{"limit": "must be a positive integer"})
+		return
+	}
+
+	cursor := c.Query("cursor")
+	customers, nextCursor, err := h.customerService.ExportCustomers(c.Request.Context(), cursor, limit)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	// Only the first chunk carries the header row, so chunks concatenate
+	// into one well-formed CSV
+	if cursor == "" {
+		_ = writer.Write([]string{"id", "code", "name", "phone", "email", "locale", "created_at"})
+	}
+	for i := range customers {
+		customer := &customers[i]
+		_ = writer.Write([]string{
+			customer.ID.String(),
+			customer.Code,
+			customer.Name,
+			customer.Phone,
+			customer.Email,
+			customer.Locale,
+			customer.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	c.Header("X-Next-Cursor", nextCursor)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// checkUnmodifiedSince enforces an If-Unmodified-Since precondition
+// against the entity's updated_at, giving timestamp-based clients a
+// concurrency guard without the version number. Returns false after
+// writing the error response; an absent header always passes.
+func checkUnmodifiedSince(c *gin.Context, updatedAt time.Time) bool {
+	header := c.GetHeader("If-Unmodified-Since")
+	if header == "" {
+		return true
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid If-Unmodified-Since header", err.Error())
+		return false
+	}
+
+	// HTTP dates only carry second precision
+	if updatedAt.Truncate(time.Second).After(since) {
+		utils.ErrorResponse(c, http.StatusPreconditionFailed, "Resource was modified since the given time", map[string]string{
+			"code": "precondition_failed",
+		})
+		return false
+	}
+	return true
+}
+
+// ensureUnmodifiedSince loads the customer and applies the
+// If-Unmodified-Since check when the client sent the header
+func (h *CustomerHandler) ensureUnmodifiedSince(c *gin.Context, id uuid.UUID) bool {
+	if c.GetHeader("If-Unmodified-Since") == "" {
+		return true
+	}
+
+	customer, err := h.customerService.GetCustomerByID(c.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return false
+	}
+	return checkUnmodifiedSince(c, customer.UpdatedAt)
+}
+
 // UpdateCustomer handles PUT /v1/customers/:id
 func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 	idStr := c.Param("id")
@@ -131,37 +278,101 @@ func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 		return
 	}
 
+	if !utils.RequireBody(c) {
+		return
+	}
+
 	var req models.UpdateCustomerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request data", err.Error())
 		return
 	}
 
-	// Get existing customer
-	customer, err := h.customerRepo.GetByID(id)
+	if !h.ensureUnmodifiedSince(c, id) {
+		return
+	}
+
+	customer, err := h.customerService.UpdateCustomer(c.Request.Context(), id, &models.Customer{
+		Code:   req.Code,
+		Name:   req.Name,
+		Phone:  req.Phone,
+		Email:  req.Email,
+		Locale: req.Locale,
+	})
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Customer not found")
-		} else {
-			utils.InternalServerErrorResponse(c, "Failed to retrieve customer", err.Error())
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Customer updated successfully", customer)
+}
+
+// applyCustomerPatch merges a partial update into the customer using JSON
+// merge semantics: nil pointers leave the field untouched, non-nil pointers
+// overwrite it (including with an empty value, which clears the field).
+func applyCustomerPatch(customer *models.Customer, req *models.PatchCustomerRequest) error {
+	if req.Code != nil && *req.Code != customer.Code {
+		return fmt.Errorf("%w: code", models.ErrImmutableField)
+	}
+	if req.Name != nil {
+		if *req.Name == "" {
+			return fmt.Errorf("%w: name cannot be cleared", models.ErrValidation)
 		}
+		customer.Name = *req.Name
+	}
+	if req.Phone != nil {
+		customer.Phone = *req.Phone
+	}
+	if req.Email != nil {
+		customer.Email = *req.Email
+	}
+	if req.Locale != nil {
+		customer.Locale = *req.Locale
+	}
+	return nil
+}
+
+// PatchCustomer handles PATCH /v1/customers/:id
+//
+// Unlike PUT, omitted fields are left unchanged while fields explicitly set
+// to an empty value are cleared.
+func (h *CustomerHandler) PatchCustomer(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid customer ID", err.Error())
+		return
+	}
+
+	if !utils.RequireBody(c) {
 		return
 	}
 
-	// Update fields if provided
-	if req.Name != "" {
-		customer.Name = req.Name
+	var req models.PatchCustomerRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data", err.Error())
+		return
 	}
-	if req.Phone != "" {
-		customer.Phone = req.Phone
+
+	customer, err := h.customerService.GetCustomerByID(c.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
 	}
-	if req.Email != "" {
-		customer.Email = req.Email
+
+	if !checkUnmodifiedSince(c, customer.UpdatedAt) {
+		return
+	}
+
+	if err := applyCustomerPatch(customer, &req); err != nil {
+		utils.RespondError(c, err)
+		return
 	}
 	customer.UpdatedAt = time.Now()
 
-	if err := h.customerRepo.Update(customer); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update customer", err.Error())
+	customer, err = h.customerService.SaveCustomer(c.Request.Context(), customer)
+	if err != nil {
+		utils.RespondError(c, err)
 		return
 	}
 
@@ -177,19 +388,12 @@ func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
 		return
 	}
 
-	// Check if customer exists
-	_, err = h.customerRepo.GetByID(id)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Customer not found")
-		} else {
-			utils.InternalServerErrorResponse(c, "Failed to retrieve customer", err.Error())
-		}
+	if !h.ensureUnmodifiedSince(c, id) {
 		return
 	}
 
-	if err := h.customerRepo.Delete(id); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete customer", err.Error())
+	if err := h.customerService.DeleteCustomer(c.Request.Context(), id); err != nil {
+		utils.RespondError(c, err)
 		return
 	}
 
@@ -0,0 +1,259 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/internal/repositories/mocks"
+	"backend/internal/services"
+	"backend/pkg/models"
+)
+
+func testCustomer() *models.Customer {
+	return &models.Customer{
+		Code:   "CUST001",
+		Name:   "John Doe",
+		Phone:  "+254700123456",
+		Email:  "john@example.com",
+		Locale: "en",
+	}
+}
+
+func TestApplyCustomerPatch_OmittedFieldsUntouched(t *testing.T) {
+	customer := testCustomer()
+
+	var req models.PatchCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"Jane Doe"}`), &req))
+
+	require.NoError(t, applyCustomerPatch(customer, &req))
+
+	assert.Equal(t, "Jane Doe", customer.Name)
+	assert.Equal(t, "+254700123456", customer.Phone)
+	assert.Equal(t, "john@example.com", customer.Email)
+}
+
+func TestApplyCustomerPatch_ExplicitEmptyClearsField(t *testing.T) {
+	customer := testCustomer()
+
+	// Omitting email leaves it alone; sending "" clears it
+	var req models.PatchCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"email":""}`), &req))
+
+	require.NoError(t, applyCustomerPatch(customer, &req))
+
+	assert.Empty(t, customer.Email)
+	assert.Equal(t, "John Doe", customer.Name)
+}
+
+func TestCheckUnmodifiedSince(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(header string, updatedAt time.Time) (bool, int) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+		if header != "" {
+			c.Request.Header.Set("If-Unmodified-Since", header)
+		}
+		ok := checkUnmodifiedSince(c, updatedAt)
+		return ok, w.Code
+	}
+
+	updatedAt := time.Now().Add(-time.Hour)
+
+	// Unchanged since the client's snapshot: proceed
+	ok, _ := run(time.Now().UTC().Format(http.TimeFormat), updatedAt)
+	assert.True(t, ok)
+
+	// Modified after the client's snapshot: 412
+	ok, code := run(time.Now().Add(-2*time.Hour).UTC().Format(http.TimeFormat), updatedAt)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusPreconditionFailed, code)
+
+	// No header: check is skipped
+	ok, _ = run("", updatedAt)
+	assert.True(t, ok)
+
+	// Garbage header: 400
+	ok, code = run("not-a-date", updatedAt)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func listCustomersRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := new(mocks.CustomerRepository)
+	repo.On("Search", mock.Anything, mock.AnythingOfType("*models.ListCustomersQuery")).
+		Return([]models.Customer{{ID: uuid.New(), Code: "CUST001", Name: "John"}}, int64(1), false, nil)
+
+	router := gin.New()
+	router.GET("/customers", NewCustomerHandler(services.NewCustomerService(repo)).ListCustomers)
+	return router
+}
+
+func TestCreateCustomer_EmptyBodyRejectedClearly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/customers", NewCustomerHandler(services.NewCustomerService(new(mocks.CustomerRepository))).CreateCustomer)
+
+	for _, body := range []string{"", "   \n\t  "} {
+		req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "request.empty_body")
+	}
+}
+
+func TestListCustomers_StructuredQueryValidationErrors(t *testing.T) {
+	router := listCustomersRouter(t)
+
+	// Out-of-range limit yields the per-field structured error
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers?limit=5000", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"validation.query"`)
+	assert.Contains(t, w.Body.String(), `"limit"`)
+	assert.Contains(t, w.Body.String(), "max")
+
+	// Non-numeric limit is also reported structurally
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers?limit=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"validation.query"`)
+}
+
+func TestListCustomers_EnvelopeByDefault(t *testing.T) {
+	router := listCustomersRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"success":true`)
+	assert.Contains(t, w.Body.String(), `"pagination"`)
+}
+
+func TestListCustomers_FlatFormat(t *testing.T) {
+	router := listCustomersRouter(t)
+
+	// Query-parameter form
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers?format=flat", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "["), "flat response should be a bare array")
+	assert.NotContains(t, w.Body.String(), `"pagination"`)
+
+	// Accept-header form
+	req := httptest.NewRequest(http.MethodGet, "/customers", nil)
+	req.Header.Set("Accept", flatContentType)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "["))
+}
+
+func TestApplyCustomerPatch_CodeIsImmutable(t *testing.T) {
+	customer := testCustomer()
+
+	var req models.PatchCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"code":"CUST999"}`), &req))
+
+	err := applyCustomerPatch(customer, &req)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, models.ErrImmutableField)
+	assert.Equal(t, "CUST001", customer.Code)
+
+	// Re-sending the current code is a no-op, not an error
+	var same models.PatchCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"code":"CUST001"}`), &same))
+	assert.NoError(t, applyCustomerPatch(customer, &same))
+}
+
+func TestApplyCustomerPatch_NameCannotBeCleared(t *testing.T) {
+	customer := testCustomer()
+
+	var req models.PatchCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"name":""}`), &req))
+
+	err := applyCustomerPatch(customer, &req)
+	require.Error(t, err)
+	assert.Equal(t, "John Doe", customer.Name)
+}
+
+func TestListCustomers_CountSkipping(t *testing.T) {
+	router := listCustomersRouter(t)
+
+	// Default includes total
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers", nil))
+	assert.Contains(t, w.Body.String(), `"total"`)
+
+	// count=false swaps total for has_more
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers?count=false", nil))
+	assert.NotContains(t, w.Body.String(), `"total"`)
+	assert.Contains(t, w.Body.String(), `"has_more"`)
+}
+
+func TestHeadCustomer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := new(mocks.CustomerRepository)
+	existing := uuid.New()
+	missing := uuid.New()
+	repo.On("ExistsByID", mock.Anything, existing).Return(true, nil)
+	repo.On("ExistsByID", mock.Anything, missing).Return(false, nil)
+
+	router := gin.New()
+	router.HEAD("/customers/:id", NewCustomerHandler(services.NewCustomerService(repo)).HeadCustomer)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/customers/"+existing.String(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/customers/"+missing.String(), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestCreateCustomer_NormalizesInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := new(mocks.CustomerRepository)
+	var stored *models.Customer
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*models.Customer")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.Customer) }).
+		Return(&models.Customer{ID: uuid.New()}, nil)
+
+	router := gin.New()
+	router.POST("/customers", NewCustomerHandler(services.NewCustomerService(repo)).CreateCustomer)
+
+	body := `{"code":"  cust001 ","name":" John  Doe ","phone":" +254700123456 "}`
+	req := httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "John Doe", stored.Name)
+	assert.Equal(t, "CUST001", stored.Code)
+	assert.Equal(t, "+254700123456", stored.Phone)
+}
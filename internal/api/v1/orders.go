@@ -1,84 +1,70 @@
 package v1
 
 import (
-	"log"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
-	"backend/internal/repositories"
 	"backend/internal/services"
 	"backend/pkg/models"
 	"backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
 type OrderHandler struct {
-	orderRepo    *repositories.OrderRepository
-	customerRepo *repositories.CustomerRepository
+	orderService *services.OrderService
 	smsService   *services.SMSService
 }
 
-func NewOrderHandler(orderRepo *repositories.OrderRepository, customerRepo *repositories.CustomerRepository, smsService *services.SMSService) *OrderHandler {
+func NewOrderHandler(orderService *services.OrderService, smsService *services.SMSService) *OrderHandler {
 	return &OrderHandler{
-		orderRepo:    orderRepo,
-		customerRepo: customerRepo,
+		orderService: orderService,
 		smsService:   smsService,
 	}
 }
 
 // CreateOrder handles POST /v1/orders
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
-	var req models.CreateOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request data", err.Error())
+	if !utils.RequireBody(c) {
 		return
 	}
 
-	// Verify customer exists
-	customer, err := h.customerRepo.GetByID(req.CustomerID)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.BadRequestResponse(c, "Customer not found", err.Error())
-		} else {
-			utils.InternalServerErrorResponse(c, "Failed to verify customer", err.Error())
-		}
+	var req models.CreateOrderRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data", err.Error())
 		return
 	}
 
-	// Set ordered_at if not provided
-	orderedAt := time.Now()
-	if req.OrderedAt != nil {
-		orderedAt = *req.OrderedAt
-	}
-
-	// Create order
-	order := &models.Order{
-		CustomerID: req.CustomerID,
-		Item:       req.Item,
-		Amount:     req.Amount,
-		OrderedAt:  orderedAt,
-		Status:     "pending",
-		Version:    1,
-		IsActive:   true,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
+	// Integrators can validate a payload end-to-end without persisting
+	if c.Query("dry_run") == "true" {
+		order, preview, err := h.orderService.PreviewOrder(c.Request.Context(), &req)
+		if err != nil {
+			if errors.Is(err, models.ErrCustomerNotFound) {
+				utils.BadRequestResponse(c, "Customer not found", err.Error())
+				return
+			}
+			utils.RespondError(c, err)
+			return
+		}
 
-	if err := h.orderRepo.Create(order); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create order", err.Error())
+		utils.SuccessResponse(c, "Order validated (dry run, nothing persisted)", gin.H{
+			"order":       order,
+			"sms_preview": preview,
+		})
 		return
 	}
 
-	// Load customer relationship for response
-	order.Customer = *customer
-
-	// Queue SMS job for background processing
-	if err := h.smsService.QueueSMS(c.Request.Context(), order); err != nil {
-		// Log error but don't fail the request
-		log.Printf("Failed to queue SMS for order %s: %v", order.ID, err)
+	order, err := h.orderService.CreateOrder(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, models.ErrCustomerNotFound) {
+			utils.BadRequestResponse(c, "Customer not found", err.Error())
+			return
+		}
+		utils.RespondError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -97,51 +83,251 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderRepo.GetByID(id)
+	order, err := h.orderService.GetOrder(c.Request.Context(), id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Order not found")
-		} else {
-			utils.InternalServerErrorResponse(c, "Failed to retrieve order", err.Error())
-		}
+		utils.RespondError(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, "Order retrieved successfully", order)
 }
 
+// GetOrderSMSPreview handles GET /v1/orders/:id/sms-preview
+//
+// Returns the exact SMS text the customer would receive for this order,
+// along with its encoding and segment count, without enqueuing anything.
+func (h *OrderHandler) GetOrderSMSPreview(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err.Error())
+		return
+	}
+
+	order, err := h.orderService.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	preview := h.smsService.PreviewOrderSMS(c.Request.Context(), order)
+	utils.SuccessResponse(c, "SMS preview generated successfully", preview)
+}
+
+// HeadOrder handles HEAD /v1/orders/:id; see HeadCustomer
+func (h *OrderHandler) HeadOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	exists, err := h.orderService.OrderExists(c.Request.Context(), id)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if exists {
+		c.Status(http.StatusOK)
+	} else {
+		c.Status(http.StatusNotFound)
+	}
+}
+
 // ListOrders handles GET /v1/orders with query parameters
 func (h *OrderHandler) ListOrders(c *gin.Context) {
 	var query models.ListOrdersQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
-		utils.BadRequestResponse(c, "Invalid query parameters", err.Error())
+		utils.RespondQueryValidationError(c, err)
 		return
 	}
 
-	// Set default pagination values
-	if query.Limit == 0 {
-		query.Limit = 20
-	}
-	if query.Limit > 100 {
-		query.Limit = 100
+	// Reject unknown status values rather than silently returning nothing
+	if query.Status != "" && !models.OrderStatus(query.Status).Valid() {
+		utils.BadRequestResponse(c, "Invalid order status", map[string]string{"status": "unknown status value"})
+		return
 	}
 
-	orders, total, err := h.orderRepo.List(&query)
+	query.Normalize()
+
+	orders, total, hasMore, err := h.orderService.ListOrders(c.Request.Context(), &query)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to retrieve orders", err.Error())
+		utils.RespondError(c, err)
+		return
+	}
+
+	// High-throughput internal consumers can opt into protobuf encoding
+	if c.GetHeader("Accept") == protobufContentType {
+		c.Data(http.StatusOK, protobufContentType, models.MarshalOrderListProto(orders, total))
+		return
+	}
+
+	// Some consumers want the bare array without the envelope
+	if wantsFlatResponse(c) {
+		c.JSON(http.StatusOK, orders)
 		return
 	}
 
+	pagination := gin.H{
+		"limit":  query.Limit,
+		"offset": query.Offset,
+	}
+	if query.Count {
+		pagination["total"] = total
+	} else {
+		pagination["has_more"] = hasMore
+	}
+
 	response := gin.H{
+		"orders":     orders,
+		"pagination": pagination,
+	}
+
+	utils.SuccessResponse(c, "Orders retrieved successfully", response)
+}
+
+// GetRecentOrders handles GET /v1/orders/recent?limit=
+//
+// Returns the most recent orders across all customers for dashboards.
+func (h *OrderHandler) GetRecentOrders(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		utils.BadRequestResponse(c, "Invalid limit parameter", map[string]string{"limit": "must be a positive integer"})
+		return
+	}
+
+	orders, err := h.orderService.RecentOrders(c.Request.Context(), limit)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Recent orders retrieved successfully", orders)
+}
+
+// ListOrdersNeedingReview handles GET /api/v1/admin/orders/review
+//
+// Returns the queue of orders flagged above the auto-processing threshold.
+func (h *OrderHandler) ListOrdersNeedingReview(c *gin.Context) {
+	var query models.ListOrdersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondQueryValidationError(c, err)
+		return
+	}
+	query.Status = string(models.OrderStatusNeedsReview)
+	query.Normalize()
+
+	orders, total, _, err := h.orderService.ListOrders(c.Request.Context(), &query)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Orders needing review retrieved successfully", gin.H{
 		"orders": orders,
 		"pagination": gin.H{
 			"total":  total,
 			"limit":  query.Limit,
 			"offset": query.Offset,
 		},
+	})
+}
+
+// PatchOrder handles PATCH /v1/orders/:id
+//
+// Corrects a pending order's item or amount before fulfilment; corrections
+// after the order leaves pending are rejected.
+func (h *OrderHandler) PatchOrder(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err.Error())
+		return
 	}
 
-	utils.SuccessResponse(c, "Orders retrieved successfully", response)
+	if !utils.RequireBody(c) {
+		return
+	}
+
+	var req models.PatchOrderRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data", err.Error())
+		return
+	}
+
+	order, err := h.orderService.CorrectOrder(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Order corrected successfully", order)
+}
+
+// GetOrderEvents handles GET /v1/orders/:id/events
+//
+// Returns the append-only lifecycle log for an order.
+func (h *OrderHandler) GetOrderEvents(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err.Error())
+		return
+	}
+
+	events, err := h.orderService.OrderEvents(c.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Order events retrieved successfully", events)
+}
+
+// BackfillSMS handles POST /api/v1/admin/orders/backfill-sms?from=&to=
+//
+// Queues confirmation SMS for un-notified orders in the range, returning
+// the count queued. Dedup keys make re-runs safe.
+func (h *OrderHandler) BackfillSMS(c *gin.Context) {
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid 'from' timestamp", map[string]string{"from": "must be RFC3339"})
+		return
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid 'to' timestamp", map[string]string{"to": "must be RFC3339"})
+		return
+	}
+
+	queued, err := h.orderService.BackfillSMS(c.Request.Context(), from, to)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "SMS backfill queued", gin.H{
+		"queued": queued,
+	})
+}
+
+// CancelOrder handles POST /v1/orders/:id/cancel
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid order ID", err.Error())
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(c.Request.Context(), id)
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, "Order cancelled successfully", order)
 }
 
 // GetCustomerOrders handles GET /v1/customers/:id/orders
@@ -153,20 +339,9 @@ func (h *OrderHandler) GetCustomerOrders(c *gin.Context) {
 		return
 	}
 
-	// Verify customer exists
-	_, err = h.customerRepo.GetByID(customerID)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.NotFoundResponse(c, "Customer not found")
-		} else {
-			utils.InternalServerErrorResponse(c, "Failed to verify customer", err.Error())
-		}
-		return
-	}
-
-	orders, err := h.orderRepo.GetByCustomerID(customerID)
+	orders, err := h.orderService.GetCustomerOrders(c.Request.Context(), customerID)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to retrieve customer orders", err.Error())
+		utils.RespondError(c, err)
 		return
 	}
 
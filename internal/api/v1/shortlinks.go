@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"net/http"
+
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShortLinkHandler resolves the short codes embedded in order SMS links
+type ShortLinkHandler struct {
+	shortLinks services.ShortLinkStore
+}
+
+func NewShortLinkHandler(shortLinks services.ShortLinkStore) *ShortLinkHandler {
+	return &ShortLinkHandler{
+		shortLinks: shortLinks,
+	}
+}
+
+// Resolve handles GET /o/:code
+//
+// Redirects the short code from an order SMS to the order resource.
+func (h *ShortLinkHandler) Resolve(c *gin.Context) {
+	link, err := h.shortLinks.GetByCode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		utils.RespondError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/api/v1/orders/"+link.OrderID.String())
+}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"backend/pkg/models"
+)
+
+// stubShortLinkStore resolves a single known code
+type stubShortLinkStore struct {
+	code    string
+	orderID uuid.UUID
+}
+
+func (s *stubShortLinkStore) GetOrCreate(ctx context.Context, orderID uuid.UUID) (*models.OrderShortLink, error) {
+	return &models.OrderShortLink{Code: s.code, OrderID: orderID}, nil
+}
+
+func (s *stubShortLinkStore) GetByCode(ctx context.Context, code string) (*models.OrderShortLink, error) {
+	if code == s.code {
+		return &models.OrderShortLink{Code: code, OrderID: s.orderID}, nil
+	}
+	return nil, models.ErrOrderNotFound
+}
+
+func TestShortLinkResolve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orderID := uuid.New()
+	store := &stubShortLinkStore{code: "abcd2345", orderID: orderID}
+
+	router := gin.New()
+	router.GET("/o/:code", NewShortLinkHandler(store).Resolve)
+
+	// A known code redirects to the order resource
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/o/abcd2345", nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/api/v1/orders/"+orderID.String(), w.Header().Get("Location"))
+
+	// Unknown codes 404
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/o/nope", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WebhookHandler receives provider callbacks (delivery reports)
+type WebhookHandler struct {
+	smsService *services.SMSService
+	// token is the shared secret callbacks must present; empty leaves the
+	// endpoint open (sandbox/dev only)
+	token string
+}
+
+func NewWebhookHandler(smsService *services.SMSService, token string) *WebhookHandler {
+	return &WebhookHandler{
+		smsService: smsService,
+		token:      token,
+	}
+}
+
+// SMSDeliveryReport handles POST /webhooks/sms/delivery
+//
+// The provider posts form-encoded delivery reports; repeated reports for
+// the same message are processed exactly once.
+func (h *WebhookHandler) SMSDeliveryReport(c *gin.Context) {
+	// Callbacks must present the shared secret so arbitrary callers can't
+	// rewrite delivery state. The token travels in a header (query strings
+	// leak into access logs) and is compared in constant time.
+	if h.token != "" &&
+		subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Webhook-Token")), []byte(h.token)) != 1 {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	jobID, err := uuid.Parse(c.PostForm("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid delivery report id", err.Error())
+		return
+	}
+
+	// The order ID is optional; reports forwarded without our metadata
+	// still update the metric and job record
+	orderID, _ := uuid.Parse(c.PostForm("order_id"))
+
+	status := c.PostForm("status")
+	delivered := status == "Delivered" || status == "Success"
+
+	if err := h.smsService.HandleDeliveryReport(c.Request.Context(), jobID, orderID, delivered); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to process delivery report", err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
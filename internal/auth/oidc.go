@@ -2,13 +2,20 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"backend/pkg/utils"
+
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -17,34 +24,110 @@ import (
 
 // OIDCConfig holds OIDC configuration
 type OIDCConfig struct {
-	IssuerURL     string
-	ClientID      string
-	ClientSecret  string
-	RedirectURL   string
-	Scopes        []string
-	VerifyOptions *oidc.Config
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// ExpectedAudiences lists additional acceptable token audiences. Some
+	// IdPs issue access tokens whose aud is an array or an API identifier
+	// different from the client ID; a token is accepted when any of its
+	// audiences matches any entry. Empty means "client ID only".
+	ExpectedAudiences []string
+	// IntrospectionURL enables RFC 7662 token introspection for opaque
+	// (non-JWT) access tokens. Empty disables introspection.
+	IntrospectionURL string
+	// AllowQueryToken additionally accepts a `token` query parameter for
+	// clients that can't set headers (browser EventSource, websockets).
+	// Off by default since URLs leak into logs more readily than headers.
+	AllowQueryToken bool
+	// ClockSkewLeeway is the tolerance applied to exp/nbf checks so minor
+	// clock drift against the IdP doesn't cause spurious 401s. Defaults to
+	// defaultClockSkewLeeway when zero.
+	ClockSkewLeeway time.Duration
+	VerifyOptions   *oidc.Config
 }
 
+// defaultClockSkewLeeway is the exp/nbf tolerance used when none is configured
+const defaultClockSkewLeeway = 30 * time.Second
+
 // OIDCProvider handles OIDC authentication
 type OIDCProvider struct {
-	config   *OIDCConfig
-	provider *oidc.Provider
-	verifier *oidc.IDTokenVerifier
-	oauth2   oauth2.Config
-	mutex    sync.RWMutex
+	config     *OIDCConfig
+	provider   *oidc.Provider
+	verifier   *oidc.IDTokenVerifier
+	oauth2     oauth2.Config
+	httpClient *http.Client
+	mutex      sync.RWMutex
+
+	// introspectionCache maps opaque tokens to their introspected claims
+	// until the token's expiry, to avoid a round-trip per request
+	introspectionCache map[string]*cachedIntrospection
+
+	// tokenCache holds recently verified token claims (keyed by token
+	// hash) so hot tokens skip signature verification on every request
+	tokenCache map[string]*cachedVerification
+
+	// verify performs the actual token verification; overridable in tests
+	verify func(ctx context.Context, rawToken string) (*Claims, error)
+
+	// registeredScopes and registeredRoles record every scope/role the
+	// route middlewares enforce, so the permissions discovery endpoint
+	// reflects what's actually wired up rather than a hand-kept list
+	registeredScopes map[string]bool
+	registeredRoles  map[string]bool
+}
+
+// tokenCacheTTL bounds how long verified claims are served from cache,
+// independent of the token's own expiry
+const tokenCacheTTL = 60 * time.Second
+
+// cachedVerification holds verified claims until the earlier of the cache
+// TTL and the token's expiry
+type cachedVerification struct {
+	claims    *Claims
+	expiresAt time.Time
+}
+
+// tokenCacheKey hashes the raw token so the cache never stores token
+// material directly
+func tokenCacheKey(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedIntrospection holds introspected claims until the token expires
+type cachedIntrospection struct {
+	claims    *Claims
+	expiresAt time.Time
+}
+
+// introspectionResponse is the RFC 7662 introspection response shape
+type introspectionResponse struct {
+	Active   bool             `json:"active"`
+	Scope    string           `json:"scope"`
+	Username string           `json:"username"`
+	Exp      int64            `json:"exp"`
+	Sub      string           `json:"sub"`
+	Aud      jwt.ClaimStrings `json:"aud"`
+	Email    string           `json:"email"`
+	Name     string           `json:"name"`
+	Roles    []string         `json:"roles"`
+	Groups   []string         `json:"groups"`
 }
 
 // Claims represents JWT claims with custom fields
 type Claims struct {
 	jwt.RegisteredClaims
 	Scope       string   `json:"scope,omitempty"`
-	Scopes      []string `json:"scp,omitempty"`        // Azure AD format
+	Scopes      []string `json:"scp,omitempty"`         // Azure AD format
 	Permissions []string `json:"permissions,omitempty"` // Auth0 format
 	Roles       []string `json:"roles,omitempty"`
 	Groups      []string `json:"groups,omitempty"`
 	Email       string   `json:"email,omitempty"`
 	Name        string   `json:"name,omitempty"`
 	Username    string   `json:"preferred_username,omitempty"`
+	TenantID    string   `json:"tenant_id,omitempty"`
 }
 
 // UserInfo contains authenticated user information
@@ -62,13 +145,14 @@ type UserInfo struct {
 // NewOIDCProvider creates a new OIDC provider
 func NewOIDCProvider(config *OIDCConfig) (*OIDCProvider, error) {
 	if config.VerifyOptions == nil {
-		config.VerifyOptions = &oidc.Config{
-			ClientID: config.ClientID,
-		}
+		config.VerifyOptions = defaultVerifyOptions(config)
 	}
 
 	provider := &OIDCProvider{
-		config: config,
+		config:             config,
+		httpClient:         &http.Client{Timeout: 10 * time.Second},
+		introspectionCache: make(map[string]*cachedIntrospection),
+		tokenCache:         make(map[string]*cachedVerification),
 	}
 
 	if err := provider.initialize(); err != nil {
@@ -78,6 +162,31 @@ func NewOIDCProvider(config *OIDCConfig) (*OIDCProvider, error) {
 	return provider, nil
 }
 
+// defaultVerifyOptions builds the verifier configuration from the
+// provider config. The verifier has no leeway knob of its own, so the
+// configured clock-skew leeway is applied by running the verifier's
+// clock behind real time; a token whose exp is within the leeway window
+// still verifies.
+func defaultVerifyOptions(config *OIDCConfig) *oidc.Config {
+	options := &oidc.Config{
+		ClientID: config.ClientID,
+	}
+	// With explicit audiences configured we do the audience check
+	// ourselves in VerifyToken, since the verifier only accepts an
+	// exact client ID match
+	if len(config.ExpectedAudiences) > 0 {
+		options.SkipClientIDCheck = true
+	}
+
+	leeway := config.ClockSkewLeeway
+	if leeway <= 0 {
+		leeway = defaultClockSkewLeeway
+	}
+	options.Now = func() time.Time { return time.Now().Add(-leeway) }
+
+	return options
+}
+
 // initialize sets up the OIDC provider and verifier
 func (p *OIDCProvider) initialize() error {
 	ctx := context.Background()
@@ -114,8 +223,54 @@ func (p *OIDCProvider) Exchange(ctx context.Context, code string) (*oauth2.Token
 	return p.oauth2.Exchange(ctx, code)
 }
 
-// VerifyToken verifies an ID token and returns claims
+// VerifyToken verifies an ID token and returns claims. Recently verified
+// tokens are served from a short-TTL in-memory cache so hot tokens don't
+// pay the signature-verification cost on every request; entries are never
+// served past the token's own expiry.
 func (p *OIDCProvider) VerifyToken(ctx context.Context, rawToken string) (*Claims, error) {
+	key := tokenCacheKey(rawToken)
+
+	p.mutex.RLock()
+	if entry, ok := p.tokenCache[key]; ok && time.Now().Before(entry.expiresAt) {
+		p.mutex.RUnlock()
+		return entry.claims, nil
+	}
+	p.mutex.RUnlock()
+
+	verify := p.verify
+	if verify == nil {
+		verify = p.verifyIDToken
+	}
+
+	claims, err := verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache until the earlier of the TTL and the token's expiry
+	expiresAt := time.Now().Add(tokenCacheTTL)
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if time.Now().Before(expiresAt) {
+		p.mutex.Lock()
+		// Evict dead entries while we hold the lock anyway, so the cache
+		// can't accumulate one entry per token ever verified
+		now := time.Now()
+		for cached, entry := range p.tokenCache {
+			if now.After(entry.expiresAt) {
+				delete(p.tokenCache, cached)
+			}
+		}
+		p.tokenCache[key] = &cachedVerification{claims: claims, expiresAt: expiresAt}
+		p.mutex.Unlock()
+	}
+
+	return claims, nil
+}
+
+// verifyIDToken performs the full OIDC verification of an ID token
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, rawToken string) (*Claims, error) {
 	// Verify with OIDC provider
 	idToken, err := p.verifier.Verify(ctx, rawToken)
 	if err != nil {
@@ -128,16 +283,134 @@ func (p *OIDCProvider) VerifyToken(ctx context.Context, rawToken string) (*Claim
 		return nil, fmt.Errorf("failed to parse claims: %w", err)
 	}
 
+	if !p.audienceAllowed(claims.Audience) {
+		return nil, fmt.Errorf("token audience %v not accepted", claims.Audience)
+	}
+
 	return &claims, nil
 }
 
-// VerifyAccessToken verifies an access token (JWT format)
+// audienceAllowed reports whether any of the token's audiences matches a
+// configured expected audience. With no expected audiences configured the
+// check is a no-op (the verifier already enforced the client ID).
+func (p *OIDCProvider) audienceAllowed(audiences []string) bool {
+	if len(p.config.ExpectedAudiences) == 0 {
+		return true
+	}
+
+	for _, audience := range audiences {
+		for _, expected := range p.config.ExpectedAudiences {
+			if audience == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VerifyAccessToken verifies the access token the request middleware
+// extracted. Opaque tokens go through (cached) RFC 7662 introspection;
+// JWTs go through VerifyToken, whose short-TTL cache means repeated
+// requests with the same hot token skip signature verification.
 func (p *OIDCProvider) VerifyAccessToken(tokenString string) (*Claims, error) {
-	// For now, use a simpler approach - this would need proper JWKS handling
-	// In production, you would fetch and cache JWKS from the provider's .well-known endpoint
-	// and validate the JWT signature properly
-	_ = tokenString // Avoid unused parameter warning
-	return nil, fmt.Errorf("access token validation not fully implemented - use ID token validation instead")
+	// Opaque (non-JWT) tokens can't be validated by signature; fall back to
+	// RFC 7662 introspection when the provider is configured for it
+	if !looksLikeJWT(tokenString) && p.config.IntrospectionURL != "" {
+		return p.IntrospectToken(context.Background(), tokenString)
+	}
+
+	return p.VerifyToken(context.Background(), tokenString)
+}
+
+// looksLikeJWT reports whether the token has the three-part structure of a
+// serialized JWT
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// IntrospectToken validates an opaque access token via the provider's
+// RFC 7662 introspection endpoint, caching the result until the token's
+// expiry
+func (p *OIDCProvider) IntrospectToken(ctx context.Context, token string) (*Claims, error) {
+	// Serve from cache while the token is still valid; keys are hashed so
+	// the cache never holds raw token material
+	cacheKey := tokenCacheKey(token)
+	p.mutex.RLock()
+	if entry, ok := p.introspectionCache[cacheKey]; ok && time.Now().Before(entry.expiresAt) {
+		p.mutex.RUnlock()
+		return entry.claims, nil
+	}
+	p.mutex.RUnlock()
+
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.IntrospectionURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.config.ClientID, p.config.ClientSecret)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+
+	if !result.Active {
+		return nil, fmt.Errorf("token is not active")
+	}
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  result.Sub,
+			Audience: result.Aud,
+		},
+		Scope:    result.Scope,
+		Email:    result.Email,
+		Name:     result.Name,
+		Username: result.Username,
+		Roles:    result.Roles,
+		Groups:   result.Groups,
+	}
+
+	expiresAt := time.Now().Add(time.Minute)
+	if result.Exp > 0 {
+		expiresAt = time.Unix(result.Exp, 0)
+		claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	}
+
+	if !p.audienceAllowed(claims.Audience) {
+		return nil, fmt.Errorf("token audience %v not accepted", claims.Audience)
+	}
+
+	p.mutex.Lock()
+	// Drop expired entries while we hold the lock so the cache doesn't
+	// accumulate one entry per token ever introspected
+	now := time.Now()
+	for key, entry := range p.introspectionCache {
+		if now.After(entry.expiresAt) {
+			delete(p.introspectionCache, key)
+		}
+	}
+	p.introspectionCache[cacheKey] = &cachedIntrospection{
+		claims:    claims,
+		expiresAt: expiresAt,
+	}
+	p.mutex.Unlock()
+
+	return claims, nil
 }
 
 // ExtractUserInfo extracts user information from claims
@@ -170,33 +443,80 @@ func (p *OIDCProvider) RequireAuth() gin.HandlerFunc {
 	return p.RequireScopes() // No specific scopes required, just authentication
 }
 
+// registerScopes records scopes enforced by some route
+func (p *OIDCProvider) registerScopes(scopes []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.registeredScopes == nil {
+		p.registeredScopes = make(map[string]bool)
+	}
+	for _, scope := range scopes {
+		p.registeredScopes[scope] = true
+	}
+}
+
+// registerRoles records roles enforced by some route
+func (p *OIDCProvider) registerRoles(roles []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.registeredRoles == nil {
+		p.registeredRoles = make(map[string]bool)
+	}
+	for _, role := range roles {
+		p.registeredRoles[role] = true
+	}
+}
+
+// Permissions returns the sorted sets of scopes and roles enforced across
+// all registered routes
+func (p *OIDCProvider) Permissions() (scopes, roles []string) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	scopes = make([]string, 0, len(p.registeredScopes))
+	for scope := range p.registeredScopes {
+		scopes = append(scopes, scope)
+	}
+	roles = make([]string, 0, len(p.registeredRoles))
+	for role := range p.registeredRoles {
+		roles = append(roles, role)
+	}
+
+	sort.Strings(scopes)
+	sort.Strings(roles)
+	return scopes, roles
+}
+
+// PermissionsHandler returns a handler listing the scopes and roles the
+// API recognizes, for frontends building permission UIs
+func (p *OIDCProvider) PermissionsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, roles := p.Permissions()
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Recognized scopes and roles",
+			"data": gin.H{
+				"scopes": scopes,
+				"roles":  roles,
+			},
+		})
+	}
+}
+
 // RequireScopes middleware that requires specific scopes
 func (p *OIDCProvider) RequireScopes(requiredScopes ...string) gin.HandlerFunc {
+	p.registerScopes(requiredScopes)
 	return func(c *gin.Context) {
-		// Extract token from Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		tokenString, ok := extractBearerToken(c, p.config.AllowQueryToken)
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "unauthorized",
-				"message": "missing authorization header",
+				"message": "missing or malformed authorization",
 			})
 			c.Abort()
 			return
 		}
 
-		// Check Bearer prefix
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "invalid authorization header format",
-			})
-			c.Abort()
-			return
-		}
-
-		tokenString := parts[1]
-
 		// Verify access token
 		claims, err := p.VerifyAccessToken(tokenString)
 		if err != nil {
@@ -209,11 +529,11 @@ func (p *OIDCProvider) RequireScopes(requiredScopes ...string) gin.HandlerFunc {
 			return
 		}
 
-		// Check token expiry
-		if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
+		// Check token validity window (with clock-skew leeway)
+		if err := p.validateTokenTiming(claims); err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "unauthorized",
-				"message": "token expired",
+				"message": err.Error(),
 			})
 			c.Abort()
 			return
@@ -225,8 +545,8 @@ func (p *OIDCProvider) RequireScopes(requiredScopes ...string) gin.HandlerFunc {
 		// Check required scopes
 		if len(requiredScopes) > 0 && !p.hasRequiredScopes(userInfo, requiredScopes) {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "forbidden",
-				"message": "insufficient scope",
+				"error":           "forbidden",
+				"message":         "insufficient scope",
 				"required_scopes": requiredScopes,
 			})
 			c.Abort()
@@ -236,15 +556,75 @@ func (p *OIDCProvider) RequireScopes(requiredScopes ...string) gin.HandlerFunc {
 		// Store user info in context
 		c.Set("user", userInfo)
 		c.Set("claims", claims)
+
+		// Scope the request to the caller's tenant when the IdP issues one
+		if claims.TenantID != "" {
+			c.Request = c.Request.WithContext(utils.WithTenant(c.Request.Context(), claims.TenantID))
+		}
+
+		// Make the authenticated subject available to the logger
+		if claims.Subject != "" {
+			c.Request = c.Request.WithContext(utils.WithUserID(c.Request.Context(), claims.Subject))
+		}
+
 		c.Next()
 	}
 }
 
+// clockSkewLeeway returns the configured exp/nbf tolerance, defaulting
+// when unset
+func (p *OIDCProvider) clockSkewLeeway() time.Duration {
+	if p.config.ClockSkewLeeway > 0 {
+		return p.config.ClockSkewLeeway
+	}
+	return defaultClockSkewLeeway
+}
+
+// validateTokenTiming checks the token's validity window, tolerating minor
+// clock drift between this server and the IdP
+func (p *OIDCProvider) validateTokenTiming(claims *Claims) error {
+	now := time.Now()
+	leeway := p.clockSkewLeeway()
+
+	if claims.ExpiresAt != nil && now.After(claims.ExpiresAt.Add(leeway)) {
+		return fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != nil && now.Add(leeway).Before(claims.NotBefore.Time) {
+		return fmt.Errorf("token not yet valid")
+	}
+	return nil
+}
+
+// extractBearerToken pulls the access token from the Authorization header,
+// accepting any casing of the Bearer scheme and tolerating extra
+// whitespace. When allowQueryToken is set, a `token` query parameter is
+// accepted as a fallback for clients that can't send headers.
+func extractBearerToken(c *gin.Context, allowQueryToken bool) (string, bool) {
+	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+	if authHeader != "" {
+		parts := strings.Fields(authHeader)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+			return parts[1], true
+		}
+		return "", false
+	}
+
+	if allowQueryToken {
+		if token := c.Query("token"); token != "" {
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
 // RequireRoles middleware that requires specific roles
 func (p *OIDCProvider) RequireRoles(requiredRoles ...string) gin.HandlerFunc {
+	p.registerRoles(requiredRoles)
+	requireAuth := p.RequireAuth()
 	return func(c *gin.Context) {
 		// First ensure authentication
-		p.RequireAuth()(c)
+		requireAuth(c)
 		if c.IsAborted() {
 			return
 		}
@@ -262,8 +642,8 @@ func (p *OIDCProvider) RequireRoles(requiredRoles ...string) gin.HandlerFunc {
 		user := userInfo.(*UserInfo)
 		if !p.hasRequiredRoles(user, requiredRoles) {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "forbidden",
-				"message": "insufficient privileges",
+				"error":          "forbidden",
+				"message":        "insufficient privileges",
 				"required_roles": requiredRoles,
 			})
 			c.Abort()
@@ -313,4 +693,4 @@ func GetCurrentUser(c *gin.Context) (*UserInfo, bool) {
 		return user.(*UserInfo), true
 	}
 	return nil, false
-}
\ No newline at end of file
+}
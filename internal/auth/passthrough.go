@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticator is the route-middleware surface SetupRoutes needs: the
+// real OIDC provider in production, or a pass-through in deployments that
+// intentionally run without authentication.
+type Authenticator interface {
+	RequireScopes(requiredScopes ...string) gin.HandlerFunc
+	RequireRoles(requiredRoles ...string) gin.HandlerFunc
+	PermissionsHandler() gin.HandlerFunc
+}
+
+// Passthrough satisfies Authenticator with no-op checks, so the API stays
+// fully routable when auth is disabled via feature flag (dev/single-tenant
+// setups). It still records the scopes and roles routes declare, keeping
+// the permissions discovery endpoint accurate.
+type Passthrough struct {
+	mutex  sync.Mutex
+	scopes map[string]bool
+	roles  map[string]bool
+}
+
+// NewPassthrough creates the no-op authenticator
+func NewPassthrough() *Passthrough {
+	return &Passthrough{
+		scopes: make(map[string]bool),
+		roles:  make(map[string]bool),
+	}
+}
+
+// RequireScopes records the scopes and lets every request through
+func (p *Passthrough) RequireScopes(requiredScopes ...string) gin.HandlerFunc {
+	p.mutex.Lock()
+	for _, scope := range requiredScopes {
+		p.scopes[scope] = true
+	}
+	p.mutex.Unlock()
+
+	return func(c *gin.Context) {
+		c.Next()
+	}
+}
+
+// RequireRoles records the roles and lets every request through
+func (p *Passthrough) RequireRoles(requiredRoles ...string) gin.HandlerFunc {
+	p.mutex.Lock()
+	for _, role := range requiredRoles {
+		p.roles[role] = true
+	}
+	p.mutex.Unlock()
+
+	return func(c *gin.Context) {
+		c.Next()
+	}
+}
+
+// PermissionsHandler mirrors the OIDC provider's discovery endpoint
+func (p *Passthrough) PermissionsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p.mutex.Lock()
+		scopes := make([]string, 0, len(p.scopes))
+		for scope := range p.scopes {
+			scopes = append(scopes, scope)
+		}
+		roles := make([]string, 0, len(p.roles))
+		for role := range p.roles {
+			roles = append(roles, role)
+		}
+		p.mutex.Unlock()
+
+		sort.Strings(scopes)
+		sort.Strings(roles)
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Recognized scopes and roles (authentication disabled)",
+			"data": gin.H{
+				"scopes": scopes,
+				"roles":  roles,
+			},
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package docs
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// schemaTypes is the registry of request/response models exposed at
+// /api/v1/schemas. Generated by reflection so the published shapes can't
+// drift from the Go structs the handlers actually bind.
+var schemaTypes = map[string]interface{}{
+	"Customer":              models.Customer{},
+	"Order":                 models.Order{},
+	"CreateCustomerRequest": models.CreateCustomerRequest{},
+	"UpdateCustomerRequest": models.UpdateCustomerRequest{},
+	"PatchCustomerRequest":  models.PatchCustomerRequest{},
+	"CreateOrderRequest":    models.CreateOrderRequest{},
+	"Response":              models.Response{},
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// SpecPaths returns every path documented in the OpenAPI spec, so tests
+// can verify the documentation matches the registered routes
+func SpecPaths() ([]string, error) {
+	var spec struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(swaggerSpec), &spec); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// SchemasHandler serves the generated JSON Schemas for all registered models
+func SchemasHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schemas := make(map[string]interface{}, len(schemaTypes))
+		for name, model := range schemaTypes {
+			schemas[name] = GenerateJSONSchema(model)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Model schemas generated from source",
+			"data":    gin.H{"schemas": schemas},
+		})
+	}
+}
+
+// GenerateJSONSchema reflects over a struct and emits a JSON Schema
+// object, using `json` tags for property names and `binding` tags for
+// required/min/max/format constraints
+func GenerateJSONSchema(model interface{}) map[string]interface{} {
+	return typeSchema(reflect.TypeOf(model), nil)
+}
+
+// typeSchema renders the schema for a type; seen guards against the
+// recursive Customer <-> Order relations
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), seen)}
+	case reflect.Map, reflect.Interface:
+		return map[string]interface{}{"type": "object"}
+	case reflect.Struct:
+		if seen[t] {
+			// Break relation cycles with an opaque object reference
+			return map[string]interface{}{"type": "object"}
+		}
+		return structSchema(t, seen)
+	default:
+		return map[string]interface{}{"type": "object"}
+	}
+}
+
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	nested := make(map[reflect.Type]bool, len(seen)+1)
+	for k := range seen {
+		nested[k] = true
+	}
+	nested[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		property := typeSchema(field.Type, nested)
+		if applyBindingConstraints(property, field.Tag.Get("binding"), field.Type) {
+			required = append(required, name)
+		}
+		if field.Type.Kind() == reflect.Ptr {
+			property["nullable"] = true
+		}
+
+		properties[name] = property
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyBindingConstraints translates gin binding tag rules into schema
+// constraints, returning whether the field is required
+func applyBindingConstraints(property map[string]interface{}, bindingTag string, fieldType reflect.Type) bool {
+	if bindingTag == "" {
+		return false
+	}
+
+	isString := fieldType.Kind() == reflect.String ||
+		(fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.String)
+
+	required := false
+	for _, rule := range strings.Split(bindingTag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			property["format"] = "email"
+		case "min":
+			if n, err := strconv.Atoi(value); err == nil {
+				if isString {
+					property["minLength"] = n
+				} else {
+					property["minimum"] = n
+				}
+			}
+		case "max":
+			if n, err := strconv.Atoi(value); err == nil {
+				if isString {
+					property["maxLength"] = n
+				} else {
+					property["maximum"] = n
+				}
+			}
+		case "gt":
+			if n, err := strconv.Atoi(value); err == nil {
+				property["exclusiveMinimum"] = n
+			}
+		}
+	}
+	return required
+}
@@ -0,0 +1,73 @@
+package docs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend/pkg/models"
+)
+
+func TestGenerateJSONSchema_CreateCustomerRequest(t *testing.T) {
+	schema := GenerateJSONSchema(models.CreateCustomerRequest{})
+
+	assert.Equal(t, "object", schema["type"])
+
+	properties, ok := schema["properties"].(map[string]interface{})
+	require.True(t, ok)
+
+	// Property names come from the json tags
+	for _, name := range []string{"code", "name", "phone", "email", "locale"} {
+		assert.Contains(t, properties, name)
+	}
+
+	// Binding constraints are reflected into the schema
+	code := properties["code"].(map[string]interface{})
+	assert.Equal(t, "string", code["type"])
+	assert.Equal(t, 2, code["minLength"])
+	assert.Equal(t, 32, code["maxLength"])
+
+	email := properties["email"].(map[string]interface{})
+	assert.Equal(t, "email", email["format"])
+
+	// Phone became optional once email-only customers were allowed, and
+	// code once server-assigned codes landed
+	required, ok := schema["required"].([]string)
+	require.True(t, ok)
+	assert.ElementsMatch(t, []string{"name"}, required)
+}
+
+func TestGenerateJSONSchema_CreateOrderRequest(t *testing.T) {
+	schema := GenerateJSONSchema(models.CreateOrderRequest{})
+	properties := schema["properties"].(map[string]interface{})
+
+	customerID := properties["customer_id"].(map[string]interface{})
+	assert.Equal(t, "string", customerID["type"])
+	assert.Equal(t, "uuid", customerID["format"])
+
+	amount := properties["amount"].(map[string]interface{})
+	assert.Equal(t, "number", amount["type"])
+	assert.Equal(t, 0, amount["exclusiveMinimum"])
+
+	orderedAt := properties["ordered_at"].(map[string]interface{})
+	assert.Equal(t, "date-time", orderedAt["format"])
+	assert.Equal(t, true, orderedAt["nullable"])
+}
+
+func TestSchemasHandler_ServesAllModels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/schemas", SchemasHandler())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/schemas", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	for name := range schemaTypes {
+		assert.Contains(t, w.Body.String(), name)
+	}
+}
@@ -1,26 +1,65 @@
 package docs
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// specETag identifies this build's spec content, so doc tooling can skip
+// refetching the multi-KB document
+var specETag = fmt.Sprintf("%q", fmt.Sprintf("%x", sha256.Sum256([]byte(swaggerSpec))))
+
+// gzippedSpec is the spec compressed once at startup
+var gzippedSpec = mustGzip(swaggerSpec)
+
+func mustGzip(content string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		panic(err)
+	}
+	if err := writer.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+// serveSpec serves the OpenAPI document with ETag/Cache-Control and gzip
+func serveSpec(c *gin.Context) {
+	c.Header("ETag", specETag)
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Vary", "Accept-Encoding")
+
+	if c.GetHeader("If-None-Match") == specETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/json", gzippedSpec)
+		return
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.String(http.StatusOK, swaggerSpec)
+}
+
 // SetupSwaggerRoutes sets up Swagger documentation routes
 func SetupSwaggerRoutes(router *gin.Engine) {
 	// OpenAPI JSON endpoint (serve at standard swagger.json path)
-	router.GET("/swagger.json", func(c *gin.Context) {
-		c.Header("Content-Type", "application/json")
-		c.String(http.StatusOK, swaggerSpec)
-	})
-	
+	router.GET("/swagger.json", serveSpec)
+
 	// Also serve at custom path for backward compatibility
-	router.GET("/api/openapi.json", func(c *gin.Context) {
-		c.Header("Content-Type", "application/json")
-		c.String(http.StatusOK, swaggerSpec)
-	})
+	router.GET("/api/openapi.json", serveSpec)
 	
 	// Swagger UI endpoint with custom URL configuration
 	url := ginSwagger.URL("/swagger.json") // The url pointing to API definition
@@ -30,6 +69,7 @@ func SetupSwaggerRoutes(router *gin.Engine) {
 	router.GET("/docs", func(c *gin.Context) {
 		c.Redirect(http.StatusPermanentRedirect, "/docs/index.html")
 	})
+}
 
 // swaggerSpec contains the OpenAPI 3.0 specification
 const swaggerSpec = `{
@@ -100,7 +140,7 @@ const swaggerSpec = `{
             "example": "CUST001",
             "pattern": "^[A-Z0-9]{4,20}$"
           },
-          "phone_number": {
+          "phone": {
             "type": "string",
             "description": "Customer phone number in international format",
             "example": "+254700123456",
@@ -503,7 +543,7 @@ const swaggerSpec = `{
                     "pattern": "^[A-Z0-9]{4,20}$",
                     "example": "CUST001"
                   },
-                  "phone_number": {
+                  "phone": {
                     "type": "string",
                     "pattern": "^\\+[1-9]\\d{1,14}$",
                     "example": "+254700123456"
@@ -646,7 +686,7 @@ const swaggerSpec = `{
                     "maxLength": 100,
                     "example": "John Doe"
                   },
-                  "phone_number": {
+                  "phone": {
                     "type": "string",
                     "pattern": "^\\+[1-9]\\d{1,14}$",
                     "example": "+254700123456"
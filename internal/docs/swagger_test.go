@@ -0,0 +1,59 @@
+package docs
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func specRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupSwaggerRoutes(router)
+	return router
+}
+
+func TestServeSpec_CachingHeaders(t *testing.T) {
+	router := specRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+
+	// A matching If-None-Match short-circuits to 304 with no body
+	req := httptest.NewRequest(http.MethodGet, "/swagger.json", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestServeSpec_Gzip(t *testing.T) {
+	router := specRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Less(t, w.Body.Len(), len(swaggerSpec))
+
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, swaggerSpec, string(decompressed))
+}
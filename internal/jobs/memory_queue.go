@@ -0,0 +1,190 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend/internal/services"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// MemoryJobQueue is an in-process implementation of services.JobQueue, for
+// single-node development and tests that shouldn't need Redis. Jobs do not
+// survive a restart.
+type MemoryJobQueue struct {
+	mu         sync.Mutex
+	jobs       map[uuid.UUID]*services.SMSJob
+	pending    map[uuid.UUID]time.Time // job ID -> scheduled-for
+	processing map[uuid.UUID]bool
+	completed  map[uuid.UUID]bool
+	failed     map[uuid.UUID]string
+	dedup      map[string]time.Time // dedup key -> claimed-at
+	heartbeat  time.Time
+}
+
+// NewMemoryJobQueue creates an empty in-memory job queue
+func NewMemoryJobQueue() *MemoryJobQueue {
+	return &MemoryJobQueue{
+		jobs:       make(map[uuid.UUID]*services.SMSJob),
+		pending:    make(map[uuid.UUID]time.Time),
+		processing: make(map[uuid.UUID]bool),
+		completed:  make(map[uuid.UUID]bool),
+		failed:     make(map[uuid.UUID]string),
+		dedup:      make(map[string]time.Time),
+	}
+}
+
+// memoryDedupWindow mirrors the Redis backend's dedup key TTL
+const memoryDedupWindow = 24 * time.Hour
+
+// Enqueue adds a job to the pending queue, honoring dedup keys
+func (m *MemoryJobQueue) Enqueue(ctx context.Context, job *services.SMSJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job.DedupKey != "" {
+		if claimedAt, ok := m.dedup[job.DedupKey]; ok {
+			if time.Since(claimedAt) < memoryDedupWindow {
+				return services.ErrDuplicateJob
+			}
+			// Expired claims are dropped so the map doesn't grow forever
+			delete(m.dedup, job.DedupKey)
+		}
+		m.dedup[job.DedupKey] = time.Now()
+	}
+
+	m.jobs[job.ID] = job
+	m.pending[job.ID] = job.ScheduledFor
+	return nil
+}
+
+// Dequeue returns the next due pending job, or nil when none is ready
+func (m *MemoryJobQueue) Dequeue(ctx context.Context) (*services.SMSJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var nextID uuid.UUID
+	var nextAt time.Time
+	found := false
+
+	for id, scheduledFor := range m.pending {
+		if scheduledFor.After(now) {
+			continue
+		}
+		if !found || scheduledFor.Before(nextAt) {
+			nextID, nextAt, found = id, scheduledFor, true
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	delete(m.pending, nextID)
+	m.processing[nextID] = true
+	return m.jobs[nextID], nil
+}
+
+// UpdateJob stores the job's current state
+func (m *MemoryJobQueue) UpdateJob(ctx context.Context, job *services.SMSJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs[job.ID] = job
+	return nil
+}
+
+// RetryJob schedules a job for another attempt after the delay. A job
+// that has exhausted its attempts is moved to failed instead.
+func (m *MemoryJobQueue) RetryJob(ctx context.Context, job *services.SMSJob, delay time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job.MaxAttempts > 0 && job.Attempts >= job.MaxAttempts {
+		job.Status = services.JobStatusFailed
+		m.jobs[job.ID] = job
+		delete(m.processing, job.ID)
+		delete(m.pending, job.ID)
+		m.failed[job.ID] = job.LastError
+		return nil
+	}
+
+	job.ScheduledFor = time.Now().Add(delay)
+	m.jobs[job.ID] = job
+	delete(m.processing, job.ID)
+	m.pending[job.ID] = job.ScheduledFor
+	return nil
+}
+
+// MarkCompleted marks a job as successfully sent
+func (m *MemoryJobQueue) MarkCompleted(ctx context.Context, jobID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.processing, jobID)
+	m.completed[jobID] = true
+	return nil
+}
+
+// MarkFailed marks a job as permanently failed
+func (m *MemoryJobQueue) MarkFailed(ctx context.Context, jobID uuid.UUID, errorMsg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.processing, jobID)
+	m.failed[jobID] = errorMsg
+	return nil
+}
+
+// Heartbeat records that the worker loop is alive
+func (m *MemoryJobQueue) Heartbeat(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.heartbeat = time.Now()
+	return nil
+}
+
+// LastHeartbeat reports the worker's most recent in-process heartbeat
+func (m *MemoryJobQueue) LastHeartbeat(ctx context.Context) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.heartbeat, nil
+}
+
+// GetStats returns queue statistics matching the Redis backend's shape
+func (m *MemoryJobQueue) GetStats(ctx context.Context) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return map[string]int64{
+		"pending":    int64(len(m.pending)),
+		"processing": int64(len(m.processing)),
+		"completed":  int64(len(m.completed)),
+		"failed":     int64(len(m.failed)),
+	}, nil
+}
+
+// NewJobQueue selects the queue backend by name. The Redis backend is the
+// default; "memory" runs without Redis but loses jobs on restart. Parts of
+// the system outside this interface observe only the Redis backend: the
+// admin job-inspection endpoints, the queue-depth backpressure middleware
+// and the SMS cost/budget counters all read Redis keys directly, so on the
+// memory backend they see an empty queue. Worker liveness goes through
+// LastHeartbeat and works on either backend.
+func NewJobQueue(backend string, client *redis.Client) (services.JobQueue, error) {
+	switch backend {
+	case "", "redis":
+		return NewRedisJobQueue(client), nil
+	case "memory":
+		return NewMemoryJobQueue(), nil
+	default:
+		return nil, fmt.Errorf("unknown job queue backend: %s", backend)
+	}
+}
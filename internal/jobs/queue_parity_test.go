@@ -0,0 +1,226 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend/internal/services"
+)
+
+// queueBackends builds one instance of every JobQueue implementation so the
+// same behavioral suite proves interface parity
+func queueBackends(t *testing.T) map[string]services.JobQueue {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]services.JobQueue{
+		"redis":  NewRedisJobQueue(client),
+		"memory": NewMemoryJobQueue(),
+	}
+}
+
+func parityJob() *services.SMSJob {
+	return &services.SMSJob{
+		ID:           uuid.New(),
+		OrderID:      uuid.New(),
+		Phone:        "+254700123456",
+		Message:      "parity test",
+		Status:       "pending",
+		MaxAttempts:  3,
+		CreatedAt:    time.Now(),
+		ScheduledFor: time.Now(),
+	}
+}
+
+func TestJobQueueParity_EnqueueDequeueComplete(t *testing.T) {
+	for name, queue := range queueBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			job := parityJob()
+
+			require.NoError(t, queue.Enqueue(ctx, job))
+
+			dequeued, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, dequeued)
+			assert.Equal(t, job.ID, dequeued.ID)
+			assert.Equal(t, job.Message, dequeued.Message)
+
+			require.NoError(t, queue.MarkCompleted(ctx, dequeued.ID))
+
+			// Nothing further to dequeue
+			empty, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, empty)
+		})
+	}
+}
+
+func TestJobQueueParity_ScheduledJobsNotDequeuedEarly(t *testing.T) {
+	for name, queue := range queueBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			job := parityJob()
+			job.ScheduledFor = time.Now().Add(time.Hour)
+
+			require.NoError(t, queue.Enqueue(ctx, job))
+
+			dequeued, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, dequeued, "future-scheduled job must not be dequeued yet")
+		})
+	}
+}
+
+func TestJobQueueParity_RetryMakesJobAvailableAgain(t *testing.T) {
+	for name, queue := range queueBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			job := parityJob()
+
+			require.NoError(t, queue.Enqueue(ctx, job))
+
+			dequeued, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, dequeued)
+
+			// Retry with no delay: the job becomes available again
+			require.NoError(t, queue.RetryJob(ctx, dequeued, 0))
+
+			again, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, again)
+			assert.Equal(t, job.ID, again.ID)
+		})
+	}
+}
+
+func TestJobQueueParity_MarkFailedStopsDelivery(t *testing.T) {
+	for name, queue := range queueBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			job := parityJob()
+
+			require.NoError(t, queue.Enqueue(ctx, job))
+
+			dequeued, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, dequeued)
+
+			require.NoError(t, queue.MarkFailed(ctx, dequeued.ID, "provider rejected"))
+
+			empty, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, empty)
+		})
+	}
+}
+
+func TestJobQueueParity_RetryOfMaxedOutJobFails(t *testing.T) {
+	for name, queue := range queueBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			job := parityJob()
+			job.Attempts = job.MaxAttempts
+			job.LastError = "still failing"
+
+			require.NoError(t, queue.Enqueue(ctx, job))
+
+			dequeued, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, dequeued)
+			dequeued.Attempts = dequeued.MaxAttempts
+
+			// RetryJob must refuse to loop an exhausted job
+			require.NoError(t, queue.RetryJob(ctx, dequeued, 0))
+
+			again, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, again, "maxed-out job must land in failed, not back in the queue")
+			assert.Equal(t, services.JobStatusFailed, dequeued.Status)
+		})
+	}
+}
+
+func TestJobQueueParity_DedupKeyEnqueuesOnce(t *testing.T) {
+	for name, queue := range queueBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			first := parityJob()
+			first.DedupKey = "order-1:order_created"
+			require.NoError(t, queue.Enqueue(ctx, first))
+
+			// Same logical notification, different job ID
+			second := parityJob()
+			second.DedupKey = "order-1:order_created"
+			err := queue.Enqueue(ctx, second)
+			assert.ErrorIs(t, err, services.ErrDuplicateJob)
+
+			// Only the first job is actually queued
+			dequeued, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, dequeued)
+			assert.Equal(t, first.ID, dequeued.ID)
+
+			empty, err := queue.Dequeue(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, empty)
+
+			// A different dedup key is unaffected
+			third := parityJob()
+			third.DedupKey = "order-2:order_created"
+			require.NoError(t, queue.Enqueue(ctx, third))
+		})
+	}
+}
+
+func TestJobQueueParity_HeartbeatRoundTrip(t *testing.T) {
+	for name, queue := range queueBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// Before any heartbeat, both backends report the zero time
+			last, err := queue.LastHeartbeat(ctx)
+			require.NoError(t, err)
+			assert.True(t, last.IsZero())
+
+			require.NoError(t, queue.Heartbeat(ctx))
+
+			last, err = queue.LastHeartbeat(ctx)
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), last, 5*time.Second)
+		})
+	}
+}
+
+func TestNewJobQueueFactory(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	redisQueue, err := NewJobQueue("redis", client)
+	require.NoError(t, err)
+	assert.IsType(t, &RedisJobQueue{}, redisQueue)
+
+	defaulted, err := NewJobQueue("", client)
+	require.NoError(t, err)
+	assert.IsType(t, &RedisJobQueue{}, defaulted)
+
+	memoryQueue, err := NewJobQueue("memory", client)
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryJobQueue{}, memoryQueue)
+
+	_, err = NewJobQueue("kafka", client)
+	assert.Error(t, err)
+}
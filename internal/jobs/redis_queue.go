@@ -3,7 +3,12 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"backend/internal/services"
@@ -11,6 +16,13 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrUnknownJobStatus is returned when a caller asks for a job status the
+// queue doesn't track
+var ErrUnknownJobStatus = errors.New("unknown job status")
+
+// ErrJobNotFound is returned when a job ID is neither tracked nor has data
+var ErrJobNotFound = errors.New("job not found")
+
 const (
 	PendingJobsQueue = "sms_jobs:pending"
 	ProcessingSet    = "sms_jobs:processing"
@@ -19,18 +31,106 @@ const (
 	RetryQueue       = "sms_jobs:retry"
 	JobDataPrefix    = "sms_job:"
 	StatsPrefix      = "sms_stats:"
+
+	// WorkerHeartbeatKey holds the unix timestamp of the worker's last loop
+	// iteration, so health checks can detect a dead worker goroutine
+	WorkerHeartbeatKey = "sms_worker:heartbeat"
+
+	// DedupKeyPrefix guards against double-enqueueing the same logical SMS
+	DedupKeyPrefix = "sms_dedup:"
+
+	// CostKeyPrefix accumulates SMS spend per UTC day
+	CostKeyPrefix = "sms_cost:"
+)
+
+// dedupWindow is how long a dedup key blocks re-enqueueing the same
+// logical notification
+const dedupWindow = 24 * time.Hour
+
+const (
+	// defaultRetryAttempts is how often a queue operation is tried before
+	// a transient Redis error is surfaced to the caller
+	defaultRetryAttempts = 3
+	// defaultRetryBackoff is the base delay between attempts (multiplied
+	// by the attempt number)
+	defaultRetryBackoff = 50 * time.Millisecond
 )
 
 // RedisJobQueue implements JobQueue interface using Redis
 type RedisJobQueue struct {
-	client *redis.Client
+	client        *redis.Client
+	retryAttempts int
+	retryBackoff  time.Duration
 }
 
 // NewRedisJobQueue creates a new Redis-based job queue
 func NewRedisJobQueue(client *redis.Client) *RedisJobQueue {
 	return &RedisJobQueue{
-		client: client,
+		client:        client,
+		retryAttempts: defaultRetryAttempts,
+		retryBackoff:  defaultRetryBackoff,
+	}
+}
+
+// Client exposes the underlying Redis client for components (rate limits,
+// locks) that share the queue's connection
+func (r *RedisJobQueue) Client() *redis.Client {
+	return r.client
+}
+
+// SetRetryPolicy overrides how transient Redis errors are retried
+func (r *RedisJobQueue) SetRetryPolicy(attempts int, backoff time.Duration) {
+	if attempts > 0 {
+		r.retryAttempts = attempts
+	}
+	if backoff > 0 {
+		r.retryBackoff = backoff
+	}
+}
+
+// isTransientRedisError reports whether an error is worth retrying: network
+// blips rather than logical failures like redis.Nil or a cancelled context
+func isTransientRedisError(err error) bool {
+	if err == nil ||
+		errors.Is(err, redis.Nil) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
 	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "i/o timeout") ||
+		strings.Contains(msg, "EOF")
+}
+
+// withRetry runs fn, retrying with linear backoff while the error looks
+// transient, so a momentary Redis blip doesn't drop an SMS job
+func (r *RedisJobQueue) withRetry(ctx context.Context, op string, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= r.retryAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt-1) * r.retryBackoff):
+			}
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+		if !isTransientRedisError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%s failed after %d attempts: %w", op, r.retryAttempts, err)
 }
 
 // Enqueue adds a job to the pending queue
@@ -42,22 +142,42 @@ func (r *RedisJobQueue) Enqueue(ctx context.Context, job *services.SMSJob) error
 		return fmt.Errorf("failed to marshal job data: %w", err)
 	}
 
-	pipe := r.client.TxPipeline()
-	
-	// Store job data with TTL (24 hours)
-	pipe.Set(ctx, jobKey, jobData, 24*time.Hour)
-	
-	// Add to pending queue with priority score (timestamp)
-	pipe.ZAdd(ctx, PendingJobsQueue, &redis.Z{
-		Score:  float64(job.ScheduledFor.Unix()),
-		Member: job.ID.String(),
+	// The dedup key is claimed with SETNX so a retried request or a
+	// double-firing reconciler enqueues the same logical SMS only once
+	if job.DedupKey != "" {
+		claimed, err := r.client.SetNX(ctx, DedupKeyPrefix+job.DedupKey, job.ID.String(), dedupWindow).Result()
+		if err != nil {
+			return fmt.Errorf("failed to claim dedup key: %w", err)
+		}
+		if !claimed {
+			return services.ErrDuplicateJob
+		}
+	}
+
+	err = r.withRetry(ctx, "enqueue", func() error {
+		pipe := r.client.TxPipeline()
+
+		// Store job data with TTL (24 hours)
+		pipe.Set(ctx, jobKey, jobData, 24*time.Hour)
+
+		// Add to pending queue with priority score (timestamp)
+		pipe.ZAdd(ctx, PendingJobsQueue, &redis.Z{
+			Score:  float64(job.ScheduledFor.Unix()),
+			Member: job.ID.String(),
+		})
+
+		// Update pending counter
+		pipe.Incr(ctx, StatsPrefix+"pending")
+
+		_, err := pipe.Exec(ctx)
+		return err
 	})
-	
-	// Update pending counter
-	pipe.Incr(ctx, StatsPrefix+"pending")
-	
-	_, err = pipe.Exec(ctx)
 	if err != nil {
+		// Release the dedup claim so the notification can be enqueued
+		// again; otherwise the key blocks retries for its whole TTL
+		if job.DedupKey != "" {
+			r.client.Del(ctx, DedupKeyPrefix+job.DedupKey)
+		}
 		return fmt.Errorf("failed to enqueue job: %w", err)
 	}
 
@@ -68,12 +188,16 @@ func (r *RedisJobQueue) Enqueue(ctx context.Context, job *services.SMSJob) error
 func (r *RedisJobQueue) Dequeue(ctx context.Context) (*services.SMSJob, error) {
 	// Get jobs that are ready to be processed (score <= current timestamp)
 	now := time.Now().Unix()
-	results, err := r.client.ZRangeByScore(ctx, PendingJobsQueue, &redis.ZRangeBy{
-		Min:   "0",
-		Max:   fmt.Sprintf("%d", now),
-		Count: 1,
-	}).Result()
-
+	var results []string
+	err := r.withRetry(ctx, "dequeue", func() error {
+		var err error
+		results, err = r.client.ZRangeByScore(ctx, PendingJobsQueue, &redis.ZRangeBy{
+			Min:   "0",
+			Max:   fmt.Sprintf("%d", now),
+			Count: 1,
+		}).Result()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch pending job: %w", err)
 	}
@@ -84,13 +208,13 @@ func (r *RedisJobQueue) Dequeue(ctx context.Context) (*services.SMSJob, error) {
 	}
 
 	jobID := results[0]
-	
+
 	// Move job from pending to processing atomically
 	pipe := r.client.TxPipeline()
 	pipe.ZRem(ctx, PendingJobsQueue, jobID)
 	pipe.SAdd(ctx, ProcessingSet, jobID)
 	pipe.Decr(ctx, StatsPrefix+"pending")
-	
+
 	_, err = pipe.Exec(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to move job to processing: %w", err)
@@ -134,12 +258,12 @@ func (r *RedisJobQueue) dequeueFromRetry(ctx context.Context) (*services.SMSJob,
 	}
 
 	jobID := results[0]
-	
+
 	// Move job from retry to processing
 	pipe := r.client.TxPipeline()
 	pipe.ZRem(ctx, RetryQueue, jobID)
 	pipe.SAdd(ctx, ProcessingSet, jobID)
-	
+
 	_, err = pipe.Exec(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to move retry job to processing: %w", err)
@@ -164,6 +288,276 @@ func (r *RedisJobQueue) dequeueFromRetry(ctx context.Context) (*services.SMSJob,
 	return &job, nil
 }
 
+// GetJob returns a single hydrated job along with its current queue status
+// and the stored error message (for failed jobs)
+func (r *RedisJobQueue) GetJob(ctx context.Context, jobID uuid.UUID) (*services.SMSJob, services.JobStatus, string, error) {
+	jobData, err := r.client.Get(ctx, JobDataPrefix+jobID.String()).Result()
+	if err == redis.Nil {
+		return nil, "", "", ErrJobNotFound
+	}
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to fetch job data: %w", err)
+	}
+
+	var job services.SMSJob
+	if err := json.Unmarshal([]byte(jobData), &job); err != nil {
+		return nil, "", "", fmt.Errorf("failed to unmarshal job data: %w", err)
+	}
+
+	status, err := r.GetJobStatus(ctx, jobID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	storedError, err := r.client.Get(ctx, "error:"+jobID.String()).Result()
+	if err == redis.Nil {
+		storedError = ""
+	} else if err != nil {
+		return nil, "", "", fmt.Errorf("failed to fetch job error: %w", err)
+	}
+
+	return &job, status, storedError, nil
+}
+
+// ListByStatus returns a page of hydrated jobs in the given status along
+// with the total number of jobs in that status.
+//
+// Sorted-set queues (pending, retry) page via ZRANGE. Plain sets
+// (processing, completed, failed) have no stable SSCAN cursor semantics, so
+// members are fetched and sorted to keep paging deterministic across calls.
+func (r *RedisJobQueue) ListByStatus(ctx context.Context, status string, offset, limit int) ([]*services.SMSJob, int64, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var jobIDs []string
+	var total int64
+
+	switch status {
+	case "pending", "retry":
+		queue := PendingJobsQueue
+		if status == "retry" {
+			queue = RetryQueue
+		}
+
+		count, err := r.client.ZCard(ctx, queue).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to count %s jobs: %w", status, err)
+		}
+		total = count
+
+		jobIDs, err = r.client.ZRange(ctx, queue, int64(offset), int64(offset+limit-1)).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list %s jobs: %w", status, err)
+		}
+
+	case "processing", "completed", "failed":
+		set := ProcessingSet
+		switch status {
+		case "completed":
+			set = CompletedSet
+		case "failed":
+			set = FailedSet
+		}
+
+		members, err := r.client.SMembers(ctx, set).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list %s jobs: %w", status, err)
+		}
+
+		sort.Strings(members)
+		total = int64(len(members))
+
+		if offset >= len(members) {
+			members = nil
+		} else {
+			end := offset + limit
+			if end > len(members) {
+				end = len(members)
+			}
+			members = members[offset:end]
+		}
+		jobIDs = members
+
+	default:
+		return nil, 0, fmt.Errorf("%w: %s", ErrUnknownJobStatus, status)
+	}
+
+	jobs := make([]*services.SMSJob, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		jobData, err := r.client.Get(ctx, JobDataPrefix+jobID).Result()
+		if err == redis.Nil {
+			// Job data expired; skip rather than failing the whole page
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to fetch job data: %w", err)
+		}
+
+		var job services.SMSJob
+		if err := json.Unmarshal([]byte(jobData), &job); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal job data: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, total, nil
+}
+
+// RequeueFailedFilter narrows which failed jobs RequeueFailed moves back to
+// pending. Zero values mean "no filter".
+type RequeueFailedFilter struct {
+	From          time.Time
+	To            time.Time
+	ErrorContains string
+}
+
+// RequeueFailed moves failed jobs matching the filter back to the pending
+// queue with reset attempt counters, returning the number requeued. Jobs
+// whose data has already expired are skipped.
+func (r *RedisJobQueue) RequeueFailed(ctx context.Context, filter RequeueFailedFilter) (int, error) {
+	jobIDs, err := r.client.SMembers(ctx, FailedSet).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list failed jobs: %w", err)
+	}
+
+	requeued := 0
+	for _, jobID := range jobIDs {
+		jobData, err := r.client.Get(ctx, JobDataPrefix+jobID).Result()
+		if err == redis.Nil {
+			// Job data expired; nothing left to retry
+			continue
+		}
+		if err != nil {
+			return requeued, fmt.Errorf("failed to fetch job data: %w", err)
+		}
+
+		var job services.SMSJob
+		if err := json.Unmarshal([]byte(jobData), &job); err != nil {
+			return requeued, fmt.Errorf("failed to unmarshal job data: %w", err)
+		}
+
+		// Apply optional filters
+		if !filter.From.IsZero() && job.LastAttempt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && job.LastAttempt.After(filter.To) {
+			continue
+		}
+		if filter.ErrorContains != "" && !strings.Contains(job.LastError, filter.ErrorContains) {
+			continue
+		}
+
+		// Reset attempt state so the job retries from scratch
+		job.Status = services.JobStatusPending
+		job.Attempts = 0
+		job.LastError = ""
+		job.ScheduledFor = time.Now()
+
+		updatedData, err := json.Marshal(&job)
+		if err != nil {
+			return requeued, fmt.Errorf("failed to marshal job data: %w", err)
+		}
+
+		pipe := r.client.TxPipeline()
+		pipe.Set(ctx, JobDataPrefix+jobID, updatedData, 24*time.Hour)
+		pipe.SRem(ctx, FailedSet, jobID)
+		pipe.Del(ctx, "error:"+jobID)
+		pipe.ZAdd(ctx, PendingJobsQueue, &redis.Z{
+			Score:  float64(job.ScheduledFor.Unix()),
+			Member: jobID,
+		})
+		pipe.Incr(ctx, StatsPrefix+"pending")
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return requeued, fmt.Errorf("failed to requeue job %s: %w", jobID, err)
+		}
+
+		requeued++
+	}
+
+	return requeued, nil
+}
+
+// PendingDepth returns the number of jobs currently waiting in the pending
+// queue, as a cheap signal for backpressure decisions
+func (r *RedisJobQueue) PendingDepth(ctx context.Context) (int64, error) {
+	return r.client.ZCard(ctx, PendingJobsQueue).Result()
+}
+
+// deliveryReportPrefix deduplicates provider delivery callbacks
+const deliveryReportPrefix = "sms_delivery:"
+
+// ClaimDeliveryReport reports whether this delivery callback is the first
+// of its kind (providers redeliver webhooks)
+func (r *RedisJobQueue) ClaimDeliveryReport(ctx context.Context, reportID string) (bool, error) {
+	claimed, err := r.client.SetNX(ctx, deliveryReportPrefix+reportID, 1, 24*time.Hour).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim delivery report: %w", err)
+	}
+	return claimed, nil
+}
+
+// ReleaseDeliveryReport frees a claim whose report failed to apply, so the
+// provider's retry is processed instead of deduplicated away
+func (r *RedisJobQueue) ReleaseDeliveryReport(ctx context.Context, reportID string) error {
+	return r.client.Del(ctx, deliveryReportPrefix+reportID).Err()
+}
+
+// costKey returns today's spend accumulator key
+func costKey() string {
+	return CostKeyPrefix + time.Now().UTC().Format("2006-01-02")
+}
+
+// AddCost accumulates SMS spend for today and returns the new total
+func (r *RedisJobQueue) AddCost(ctx context.Context, amount float64) (float64, error) {
+	key := costKey()
+	total, err := r.client.IncrByFloat(ctx, key, amount).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to accumulate SMS cost: %w", err)
+	}
+	// Keep a couple of days for inspection, then let the keys expire
+	r.client.Expire(ctx, key, 48*time.Hour)
+	return total, nil
+}
+
+// TodaySpend returns the SMS spend accumulated today
+func (r *RedisJobQueue) TodaySpend(ctx context.Context) (float64, error) {
+	spend, err := r.client.Get(ctx, costKey()).Float64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read SMS spend: %w", err)
+	}
+	return spend, nil
+}
+
+// Heartbeat records that the worker loop is alive
+func (r *RedisJobQueue) Heartbeat(ctx context.Context) error {
+	return r.client.Set(ctx, WorkerHeartbeatKey, time.Now().Unix(), 0).Err()
+}
+
+// LastHeartbeat reads back the worker's most recent heartbeat; a missing
+// key yields the zero time so callers can tell "never" from "stale"
+func (r *RedisJobQueue) LastHeartbeat(ctx context.Context) (time.Time, error) {
+	value, err := r.client.Get(ctx, WorkerHeartbeatKey).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read worker heartbeat: %w", err)
+	}
+	timestamp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid worker heartbeat value %q", value)
+	}
+	return time.Unix(timestamp, 0), nil
+}
+
 // UpdateJob updates job data in Redis
 func (r *RedisJobQueue) UpdateJob(ctx context.Context, job *services.SMSJob) error {
 	jobKey := JobDataPrefix + job.ID.String()
@@ -181,26 +575,35 @@ func (r *RedisJobQueue) UpdateJob(ctx context.Context, job *services.SMSJob) err
 	return nil
 }
 
-// RetryJob schedules a job for retry
+// RetryJob schedules a job for retry. A job that has exhausted its
+// attempts is moved to failed instead, so no caller can loop it forever.
 func (r *RedisJobQueue) RetryJob(ctx context.Context, job *services.SMSJob, delay time.Duration) error {
+	if job.MaxAttempts > 0 && job.Attempts >= job.MaxAttempts {
+		job.Status = services.JobStatusFailed
+		if err := r.UpdateJob(ctx, job); err != nil {
+			return err
+		}
+		return r.MarkFailed(ctx, job.ID, job.LastError)
+	}
+
 	job.ScheduledFor = time.Now().Add(delay)
-	
+
 	// Update job data
 	if err := r.UpdateJob(ctx, job); err != nil {
 		return err
 	}
 
 	pipe := r.client.TxPipeline()
-	
+
 	// Remove from processing
 	pipe.SRem(ctx, ProcessingSet, job.ID.String())
-	
+
 	// Add to retry queue with delayed timestamp
 	pipe.ZAdd(ctx, RetryQueue, &redis.Z{
 		Score:  float64(job.ScheduledFor.Unix()),
 		Member: job.ID.String(),
 	})
-	
+
 	_, err := pipe.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to schedule job for retry: %w", err)
@@ -212,17 +615,17 @@ func (r *RedisJobQueue) RetryJob(ctx context.Context, job *services.SMSJob, dela
 // MarkCompleted marks a job as completed
 func (r *RedisJobQueue) MarkCompleted(ctx context.Context, jobID uuid.UUID) error {
 	pipe := r.client.TxPipeline()
-	
+
 	// Remove from processing and add to completed
 	pipe.SRem(ctx, ProcessingSet, jobID.String())
 	pipe.SAdd(ctx, CompletedSet, jobID.String())
-	
+
 	// Update stats
 	pipe.Incr(ctx, StatsPrefix+"sent")
-	
+
 	// Set TTL for completed jobs (keep for 7 days)
 	pipe.Expire(ctx, JobDataPrefix+jobID.String(), 7*24*time.Hour)
-	
+
 	_, err := pipe.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to mark job as completed: %w", err)
@@ -234,20 +637,20 @@ func (r *RedisJobQueue) MarkCompleted(ctx context.Context, jobID uuid.UUID) erro
 // MarkFailed marks a job as failed
 func (r *RedisJobQueue) MarkFailed(ctx context.Context, jobID uuid.UUID, errorMsg string) error {
 	pipe := r.client.TxPipeline()
-	
+
 	// Remove from processing and add to failed
 	pipe.SRem(ctx, ProcessingSet, jobID.String())
 	pipe.SAdd(ctx, FailedSet, jobID.String())
-	
+
 	// Update stats
 	pipe.Incr(ctx, StatsPrefix+"failed")
-	
+
 	// Store error message
 	pipe.Set(ctx, "error:"+jobID.String(), errorMsg, 7*24*time.Hour)
-	
+
 	// Set TTL for failed jobs (keep for 7 days)
 	pipe.Expire(ctx, JobDataPrefix+jobID.String(), 7*24*time.Hour)
-	
+
 	_, err := pipe.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to mark job as failed: %w", err)
@@ -259,18 +662,20 @@ func (r *RedisJobQueue) MarkFailed(ctx context.Context, jobID uuid.UUID, errorMs
 // GetStats returns job queue statistics
 func (r *RedisJobQueue) GetStats(ctx context.Context) (map[string]int64, error) {
 	pipe := r.client.Pipeline()
-	
+
 	pendingCmd := pipe.ZCard(ctx, PendingJobsQueue)
 	retryCmd := pipe.ZCard(ctx, RetryQueue)
 	processingCmd := pipe.SCard(ctx, ProcessingSet)
 	completedCmd := pipe.SCard(ctx, CompletedSet)
 	failedCmd := pipe.SCard(ctx, FailedSet)
-	
+
 	// Get counters
 	sentCmd := pipe.Get(ctx, StatsPrefix+"sent")
-	
+
+	// A missing counter key surfaces as redis.Nil from Exec; that just
+	// means nothing has been sent yet
 	_, err := pipe.Exec(ctx)
-	if err != nil {
+	if err != nil && err != redis.Nil {
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
 
@@ -289,54 +694,114 @@ func (r *RedisJobQueue) GetStats(ctx context.Context) (map[string]int64, error)
 	}, nil
 }
 
+// ReapOrphanedJobData deletes sms_job:* data keys whose job ID is no
+// longer a member of any queue or status set (e.g. after a crash between
+// pipeline steps), along with their error:* keys. Returns how many orphans
+// were removed.
+func (r *RedisJobQueue) ReapOrphanedJobData(ctx context.Context) (int, error) {
+	reaped := 0
+	var cursor uint64
+
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, JobDataPrefix+"*", 100).Result()
+		if err != nil {
+			return reaped, fmt.Errorf("failed to scan job data keys: %w", err)
+		}
+
+		for _, key := range keys {
+			jobID := strings.TrimPrefix(key, JobDataPrefix)
+
+			tracked, err := r.jobTracked(ctx, jobID)
+			if err != nil {
+				return reaped, err
+			}
+			if tracked {
+				continue
+			}
+
+			if err := r.client.Del(ctx, key, "error:"+jobID).Err(); err != nil {
+				return reaped, fmt.Errorf("failed to delete orphaned job data %s: %w", key, err)
+			}
+			reaped++
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return reaped, nil
+}
+
+// jobTracked reports whether a job ID is a member of any queue or status set
+func (r *RedisJobQueue) jobTracked(ctx context.Context, jobID string) (bool, error) {
+	if err := r.client.ZScore(ctx, PendingJobsQueue, jobID).Err(); err == nil {
+		return true, nil
+	} else if err != redis.Nil {
+		return false, err
+	}
+
+	if err := r.client.ZScore(ctx, RetryQueue, jobID).Err(); err == nil {
+		return true, nil
+	} else if err != redis.Nil {
+		return false, err
+	}
+
+	for _, set := range []string{ProcessingSet, CompletedSet, FailedSet} {
+		member, err := r.client.SIsMember(ctx, set, jobID).Result()
+		if err != nil {
+			return false, err
+		}
+		if member {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // CleanupExpiredJobs removes old completed and failed jobs
 func (r *RedisJobQueue) CleanupExpiredJobs(ctx context.Context) error {
 	// This is handled by Redis TTL, but we can add additional cleanup logic here
 	// For example, removing very old entries from sets
-	
+
 	cutoffTime := time.Now().AddDate(0, 0, -30).Unix() // 30 days ago
-	
+
 	pipe := r.client.TxPipeline()
-	
+
 	// Remove old entries from completed and failed sets
 	// This would require storing timestamp info, so for now just return nil
 	_ = cutoffTime
 	_ = pipe
-	
+
 	return nil
 }
 
-// GetJobStatus returns the status of a specific job
-func (r *RedisJobQueue) GetJobStatus(ctx context.Context, jobID uuid.UUID) (string, error) {
+// GetJobStatus returns the typed status of a specific job
+func (r *RedisJobQueue) GetJobStatus(ctx context.Context, jobID uuid.UUID) (services.JobStatus, error) {
 	jobIDStr := jobID.String()
-	
+
 	// Check each set to determine status
-	isPending, err := r.client.ZScore(ctx, PendingJobsQueue, jobIDStr).Result()
-	if err == nil {
-		_ = isPending
-		return "pending", nil
-	}
-	
-	isRetry, err := r.client.ZScore(ctx, RetryQueue, jobIDStr).Result()
-	if err == nil {
-		_ = isRetry
-		return "retry", nil
-	}
-	
-	isProcessing, err := r.client.SIsMember(ctx, ProcessingSet, jobIDStr).Result()
-	if err == nil && isProcessing {
-		return "processing", nil
-	}
-	
-	isCompleted, err := r.client.SIsMember(ctx, CompletedSet, jobIDStr).Result()
-	if err == nil && isCompleted {
-		return "completed", nil
-	}
-	
-	isFailed, err := r.client.SIsMember(ctx, FailedSet, jobIDStr).Result()
-	if err == nil && isFailed {
-		return "failed", nil
-	}
-	
-	return "unknown", nil
-}
\ No newline at end of file
+	if err := r.client.ZScore(ctx, PendingJobsQueue, jobIDStr).Err(); err == nil {
+		return services.JobStatusPending, nil
+	}
+
+	if err := r.client.ZScore(ctx, RetryQueue, jobIDStr).Err(); err == nil {
+		return services.JobStatusRetry, nil
+	}
+
+	if isProcessing, err := r.client.SIsMember(ctx, ProcessingSet, jobIDStr).Result(); err == nil && isProcessing {
+		return services.JobStatusProcessing, nil
+	}
+
+	if isCompleted, err := r.client.SIsMember(ctx, CompletedSet, jobIDStr).Result(); err == nil && isCompleted {
+		return services.JobStatusSent, nil
+	}
+
+	if isFailed, err := r.client.SIsMember(ctx, FailedSet, jobIDStr).Result(); err == nil && isFailed {
+		return services.JobStatusFailed, nil
+	}
+
+	return services.JobStatusUnknown, nil
+}
@@ -0,0 +1,331 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend/internal/services"
+)
+
+// newTestQueue starts an in-process Redis server and returns a queue backed by it
+func newTestQueue(t *testing.T) (*RedisJobQueue, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisJobQueue(client), client
+}
+
+// seedFailedJob stores a failed job in Redis the same way MarkFailed would
+func seedFailedJob(t *testing.T, client *redis.Client, lastError string, lastAttempt time.Time) *services.SMSJob {
+	t.Helper()
+	ctx := context.Background()
+
+	job := &services.SMSJob{
+		ID:          uuid.New(),
+		OrderID:     uuid.New(),
+		CustomerID:  uuid.New(),
+		Phone:       "+254700123456",
+		Message:     "Hello! Your order has been received.",
+		Status:      "failed",
+		Attempts:    3,
+		MaxAttempts: 3,
+		LastError:   lastError,
+		CreatedAt:   lastAttempt.Add(-time.Minute),
+		LastAttempt: lastAttempt,
+	}
+
+	jobData, err := json.Marshal(job)
+	require.NoError(t, err)
+
+	jobID := job.ID.String()
+	require.NoError(t, client.Set(ctx, JobDataPrefix+jobID, jobData, 24*time.Hour).Err())
+	require.NoError(t, client.SAdd(ctx, FailedSet, jobID).Err())
+	require.NoError(t, client.Set(ctx, "error:"+jobID, lastError, 24*time.Hour).Err())
+
+	return job
+}
+
+func TestRequeueFailed_MovesJobsToPending(t *testing.T) {
+	queue, client := newTestQueue(t)
+	ctx := context.Background()
+
+	job1 := seedFailedJob(t, client, "HTTP request failed", time.Now())
+	job2 := seedFailedJob(t, client, "SMS API error", time.Now())
+
+	requeued, err := queue.RequeueFailed(ctx, RequeueFailedFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, requeued)
+
+	// Failed set is drained and both jobs are pending
+	failedCount, err := client.SCard(ctx, FailedSet).Result()
+	require.NoError(t, err)
+	assert.Zero(t, failedCount)
+
+	pendingCount, err := client.ZCard(ctx, PendingJobsQueue).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pendingCount)
+
+	// Attempt counters are reset on the stored job data
+	for _, job := range []*services.SMSJob{job1, job2} {
+		jobData, err := client.Get(ctx, JobDataPrefix+job.ID.String()).Result()
+		require.NoError(t, err)
+
+		var stored services.SMSJob
+		require.NoError(t, json.Unmarshal([]byte(jobData), &stored))
+		assert.Equal(t, services.JobStatusPending, stored.Status)
+		assert.Zero(t, stored.Attempts)
+		assert.Empty(t, stored.LastError)
+	}
+}
+
+func TestRequeueFailed_FiltersByErrorSubstring(t *testing.T) {
+	queue, client := newTestQueue(t)
+	ctx := context.Background()
+
+	matching := seedFailedJob(t, client, "SMS API error: InsufficientBalance", time.Now())
+	seedFailedJob(t, client, "HTTP request failed: connection refused", time.Now())
+
+	requeued, err := queue.RequeueFailed(ctx, RequeueFailedFilter{ErrorContains: "InsufficientBalance"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+
+	// Only the matching job moved to pending
+	pending, err := client.ZRange(ctx, PendingJobsQueue, 0, -1).Result()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, matching.ID.String(), pending[0])
+
+	failedCount, err := client.SCard(ctx, FailedSet).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), failedCount)
+}
+
+func TestRequeueFailed_FiltersByTimeRange(t *testing.T) {
+	queue, client := newTestQueue(t)
+	ctx := context.Background()
+
+	recent := seedFailedJob(t, client, "HTTP request failed", time.Now())
+	seedFailedJob(t, client, "HTTP request failed", time.Now().Add(-48*time.Hour))
+
+	requeued, err := queue.RequeueFailed(ctx, RequeueFailedFilter{From: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+
+	pending, err := client.ZRange(ctx, PendingJobsQueue, 0, -1).Result()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, recent.ID.String(), pending[0])
+}
+
+func TestListByStatus_PagesAcrossFailedJobs(t *testing.T) {
+	queue, client := newTestQueue(t)
+	ctx := context.Background()
+
+	seen := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		job := seedFailedJob(t, client, "HTTP request failed", time.Now())
+		seen[job.ID.String()] = false
+	}
+
+	// Walk the pages and make sure every job shows up exactly once
+	for offset := 0; offset < 5; offset += 2 {
+		jobs, total, err := queue.ListByStatus(ctx, "failed", offset, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), total)
+
+		for _, job := range jobs {
+			id := job.ID.String()
+			assert.False(t, seen[id], "job %s returned on more than one page", id)
+			seen[id] = true
+		}
+	}
+
+	for id, found := range seen {
+		assert.True(t, found, "job %s missing from paged results", id)
+	}
+}
+
+func TestListByStatus_PendingUsesQueueOrder(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		job := &services.SMSJob{
+			ID:           uuid.New(),
+			Phone:        "+254700123456",
+			Message:      "test",
+			Status:       "pending",
+			MaxAttempts:  3,
+			CreatedAt:    time.Now(),
+			ScheduledFor: time.Now().Add(time.Duration(i) * time.Minute),
+		}
+		require.NoError(t, queue.Enqueue(ctx, job))
+	}
+
+	jobs, total, err := queue.ListByStatus(ctx, "pending", 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, jobs, 2)
+
+	rest, _, err := queue.ListByStatus(ctx, "pending", 2, 2)
+	require.NoError(t, err)
+	assert.Len(t, rest, 1)
+}
+
+func TestListByStatus_RejectsUnknownStatus(t *testing.T) {
+	queue, _ := newTestQueue(t)
+
+	_, _, err := queue.ListByStatus(context.Background(), "bogus", 0, 10)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrUnknownJobStatus)
+}
+
+func TestGetJob_ReturnsFailedJobWithError(t *testing.T) {
+	queue, client := newTestQueue(t)
+	ctx := context.Background()
+
+	seeded := seedFailedJob(t, client, "SMS API error: InvalidPhoneNumber", time.Now())
+
+	job, status, storedError, err := queue.GetJob(ctx, seeded.ID)
+	require.NoError(t, err)
+	assert.Equal(t, seeded.ID, job.ID)
+	assert.Equal(t, services.JobStatusFailed, status)
+	assert.Equal(t, "SMS API error: InvalidPhoneNumber", storedError)
+}
+
+func TestGetJob_UnknownIDNotFound(t *testing.T) {
+	queue, _ := newTestQueue(t)
+
+	_, _, _, err := queue.GetJob(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestReapOrphanedJobData(t *testing.T) {
+	queue, client := newTestQueue(t)
+	ctx := context.Background()
+
+	// A legitimate pending job must survive the reaper
+	legit := &services.SMSJob{
+		ID:           uuid.New(),
+		Phone:        "+254700123456",
+		Message:      "keep me",
+		Status:       "pending",
+		MaxAttempts:  3,
+		ScheduledFor: time.Now(),
+	}
+	require.NoError(t, queue.Enqueue(ctx, legit))
+
+	// An orphan: data + error keys but no set membership anywhere
+	orphanID := uuid.New().String()
+	require.NoError(t, client.Set(ctx, JobDataPrefix+orphanID, `{"id":"`+orphanID+`"}`, 0).Err())
+	require.NoError(t, client.Set(ctx, "error:"+orphanID, "boom", 0).Err())
+
+	reaped, err := queue.ReapOrphanedJobData(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reaped)
+
+	// Orphan data and error keys are gone
+	assert.Equal(t, redis.Nil, client.Get(ctx, JobDataPrefix+orphanID).Err())
+	assert.Equal(t, redis.Nil, client.Get(ctx, "error:"+orphanID).Err())
+
+	// The tracked job's data is untouched
+	assert.NoError(t, client.Get(ctx, JobDataPrefix+legit.ID.String()).Err())
+}
+
+func TestWithRetry_TransientErrorSucceedsOnSecondTry(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	queue.SetRetryPolicy(3, time.Millisecond)
+
+	calls := 0
+	err := queue.withRetry(context.Background(), "test-op", func() error {
+		calls++
+		if calls == 1 {
+			return errors.New("dial tcp 127.0.0.1:6379: connection refused")
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestWithRetry_PermanentErrorNotRetried(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	queue.SetRetryPolicy(3, time.Millisecond)
+
+	calls := 0
+	err := queue.withRetry(context.Background(), "test-op", func() error {
+		calls++
+		return errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithRetry_ExhaustedAttempts(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	queue.SetRetryPolicy(2, time.Millisecond)
+
+	calls := 0
+	err := queue.withRetry(context.Background(), "test-op", func() error {
+		calls++
+		return errors.New("i/o timeout")
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Contains(t, err.Error(), "test-op failed after 2 attempts")
+}
+
+func TestIsTransientRedisError(t *testing.T) {
+	assert.False(t, isTransientRedisError(nil))
+	assert.False(t, isTransientRedisError(redis.Nil))
+	assert.False(t, isTransientRedisError(context.Canceled))
+	assert.True(t, isTransientRedisError(errors.New("read tcp: connection reset by peer")))
+	assert.True(t, isTransientRedisError(errors.New("unexpected EOF")))
+	assert.False(t, isTransientRedisError(errors.New("ERR syntax error")))
+}
+
+func TestRequeueFailed_SkipsExpiredJobData(t *testing.T) {
+	queue, client := newTestQueue(t)
+	ctx := context.Background()
+
+	// A job ID in the failed set whose data key has already expired
+	require.NoError(t, client.SAdd(ctx, FailedSet, uuid.New().String()).Err())
+
+	requeued, err := queue.RequeueFailed(ctx, RequeueFailedFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, requeued)
+}
+
+func TestCostTracking(t *testing.T) {
+	queue, _ := newTestQueue(t)
+	ctx := context.Background()
+
+	spend, err := queue.TodaySpend(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, spend)
+
+	total, err := queue.AddCost(ctx, 0.8)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.8, total, 0.001)
+
+	total, err = queue.AddCost(ctx, 0.8)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.6, total, 0.001)
+
+	spend, err = queue.TodaySpend(ctx)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.6, spend, 0.001)
+}
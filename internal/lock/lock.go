@@ -0,0 +1,97 @@
+// Package lock implements a Redis-backed distributed lock for work that
+// must run on only one instance (reconciler, reaper, singleton collectors).
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// releaseScript deletes the lock only when the caller still owns it, so an
+// instance that lost the lock to expiry can't delete its successor's claim
+var releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+else
+	return 0
+end`)
+
+// renewScript extends the TTL only while the caller still owns the lock
+var renewScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+else
+	return 0
+end`)
+
+// Lock is a single named distributed lock. Each Lock instance holds its
+// own fencing token; two instances contending for the same key exclude
+// each other.
+type Lock struct {
+	client *redis.Client
+	key    string
+	ttl    time.Duration
+	token  string
+}
+
+// New creates a lock on the given key with the given TTL
+func New(client *redis.Client, key string, ttl time.Duration) *Lock {
+	return &Lock{
+		client: client,
+		key:    key,
+		ttl:    ttl,
+		token:  uuid.New().String(),
+	}
+}
+
+// Acquire tries to claim the lock, reporting whether this instance now
+// holds it
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	acquired, err := l.client.SetNX(ctx, l.key, l.token, l.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", l.key, err)
+	}
+	return acquired, nil
+}
+
+// Renew extends the TTL, reporting whether the lock was still held
+func (l *Lock) Renew(ctx context.Context) (bool, error) {
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lock %s: %w", l.key, err)
+	}
+	return renewed == 1, nil
+}
+
+// Release frees the lock if this instance still owns it
+func (l *Lock) Release(ctx context.Context) error {
+	if _, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Result(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	return nil
+}
+
+// AutoRenew keeps the lock alive in the background until the context is
+// cancelled or a renewal discovers the lock was lost
+func (l *Lock) AutoRenew(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				held, err := l.Renew(ctx)
+				if err != nil || !held {
+					return
+				}
+			}
+		}
+	}()
+}
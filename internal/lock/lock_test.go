@@ -0,0 +1,93 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lockFixture(t *testing.T, ttl time.Duration) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, mr
+}
+
+func TestLock_AcquireContendRelease(t *testing.T) {
+	client, _ := lockFixture(t, time.Minute)
+	ctx := context.Background()
+
+	first := New(client, "locks:test", time.Minute)
+	second := New(client, "locks:test", time.Minute)
+
+	held, err := first.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// A second instance can't claim the same key
+	held, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	// After release, the contender succeeds
+	require.NoError(t, first.Release(ctx))
+	held, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+}
+
+func TestLock_ReleaseOnlyDeletesOwnClaim(t *testing.T) {
+	client, mr := lockFixture(t, time.Minute)
+	ctx := context.Background()
+
+	first := New(client, "locks:test", time.Minute)
+	held, err := first.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	// The first holder's claim expires and another instance takes over
+	mr.FastForward(2 * time.Minute)
+	second := New(client, "locks:test", time.Minute)
+	held, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	// The stale holder's release must not delete the new claim
+	require.NoError(t, first.Release(ctx))
+	stillHeld, err := second.Renew(ctx)
+	require.NoError(t, err)
+	assert.True(t, stillHeld)
+}
+
+func TestLock_RenewExtendsTTL(t *testing.T) {
+	client, mr := lockFixture(t, time.Minute)
+	ctx := context.Background()
+
+	l := New(client, "locks:test", time.Minute)
+	held, err := l.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, held)
+
+	mr.FastForward(45 * time.Second)
+	renewed, err := l.Renew(ctx)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+
+	// Past the original TTL but within the renewed one: still held
+	mr.FastForward(45 * time.Second)
+	renewed, err = l.Renew(ctx)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+
+	// Once truly expired, renewal reports the lock lost
+	mr.FastForward(2 * time.Minute)
+	renewed, err = l.Renew(ctx)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
+	"backend/internal/jobs"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 )
@@ -38,28 +41,174 @@ type HealthResponse struct {
 	Components map[string]ComponentHealth    `json:"components"`
 }
 
+// defaultWorkerHeartbeatThreshold is how stale the SMS worker heartbeat may
+// be before the worker is reported unhealthy
+const defaultWorkerHeartbeatThreshold = 90 * time.Second
+
+// defaultHealthCacheTTL is how long a computed health result is reused, so
+// bursts of Kubernetes probes don't re-ping every dependency
+const defaultHealthCacheTTL = 2 * time.Second
+
 // HealthChecker manages health checks for various components
 type HealthChecker struct {
-	db         *sql.DB
-	redis      *redis.Client
-	startTime  time.Time
-	version    string
-	logger     *Logger
+	db                 *sql.DB
+	redis              *redis.Client
+	startTime          time.Time
+	version            string
+	logger             *Logger
+	heartbeatThreshold time.Duration
+
+	// heartbeatSource reads the worker's last heartbeat through the
+	// selected queue backend; nil falls back to the Redis key directly
+	heartbeatSource func(ctx context.Context) (time.Time, error)
+
+	cacheTTL time.Duration
+	cacheMu  sync.Mutex
+	cached   *HealthResponse
+	cachedAt time.Time
+
+	// softComponents are excluded from readiness: the API can still serve
+	// traffic when they are down (e.g. the SMS provider)
+	softComponents map[string]bool
+
+	// history keeps the last N results per component for the status page
+	historyMu sync.Mutex
+	history   map[string][]HealthStatus
 }
 
+// healthHistorySize is how many recent results are kept per component
+const healthHistorySize = 20
+
 // NewHealthChecker creates a new health checker
 func NewHealthChecker(db *sql.DB, redisClient *redis.Client, version string, logger *Logger) *HealthChecker {
 	return &HealthChecker{
-		db:        db,
-		redis:     redisClient,
-		startTime: time.Now(),
-		version:   version,
-		logger:    logger,
+		db:                 db,
+		redis:              redisClient,
+		startTime:          time.Now(),
+		version:            version,
+		logger:             logger,
+		heartbeatThreshold: defaultWorkerHeartbeatThreshold,
+		cacheTTL:           defaultHealthCacheTTL,
+		softComponents: map[string]bool{
+			"sms_service": true,
+			"sms_worker":  true,
+		},
+		history: make(map[string][]HealthStatus),
+	}
+}
+
+// SetWorkerHeartbeatSource routes the SMS worker liveness check through
+// the configured queue backend instead of the raw Redis key, so the
+// memory backend's in-process heartbeat is honored too
+func (hc *HealthChecker) SetWorkerHeartbeatSource(source func(ctx context.Context) (time.Time, error)) {
+	hc.heartbeatSource = source
+}
+
+// recordHistory appends each component's latest status to its rolling window
+func (hc *HealthChecker) recordHistory(components map[string]ComponentHealth) {
+	hc.historyMu.Lock()
+	defer hc.historyMu.Unlock()
+
+	for name, component := range components {
+		window := append(hc.history[name], component.Status)
+		if len(window) > healthHistorySize {
+			window = window[len(window)-healthHistorySize:]
+		}
+		hc.history[name] = window
+	}
+}
+
+// componentUptime is the percentage of recent checks where the component
+// was serving (healthy or degraded)
+func componentUptime(statuses []HealthStatus) float64 {
+	if len(statuses) == 0 {
+		return 0
+	}
+	up := 0
+	for _, status := range statuses {
+		if status != StatusUnhealthy {
+			up++
+		}
 	}
+	return float64(up) / float64(len(statuses)) * 100
 }
 
-// CheckHealth performs all health checks and returns the overall status
+// HistoryHandler returns per-component uptime percentages and the recent
+// status window, for status pages
+func (hc *HealthChecker) HistoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hc.historyMu.Lock()
+		summary := make(map[string]gin.H, len(hc.history))
+		for name, statuses := range hc.history {
+			recent := append([]HealthStatus(nil), statuses...)
+			summary[name] = gin.H{
+				"recent":         recent,
+				"checks":         len(recent),
+				"uptime_percent": componentUptime(recent),
+			}
+		}
+		hc.historyMu.Unlock()
+
+		c.JSON(http.StatusOK, gin.H{
+			"timestamp":  time.Now().UTC(),
+			"components": summary,
+		})
+	}
+}
+
+// SetSoftComponents overrides which components are excluded from readiness
+func (hc *HealthChecker) SetSoftComponents(names []string) {
+	soft := make(map[string]bool, len(names))
+	for _, name := range names {
+		soft[name] = true
+	}
+	hc.softComponents = soft
+}
+
+// readinessStatus determines readiness from hard dependencies only: a down
+// SMS provider shouldn't stop the API from serving reads
+func (hc *HealthChecker) readinessStatus(components map[string]ComponentHealth) HealthStatus {
+	hard := make(map[string]ComponentHealth, len(components))
+	for name, component := range components {
+		if !hc.softComponents[name] {
+			hard[name] = component
+		}
+	}
+	return hc.determineOverallStatus(hard)
+}
+
+// SetCacheTTL overrides how long health results are cached; 0 disables
+// caching entirely
+func (hc *HealthChecker) SetCacheTTL(ttl time.Duration) {
+	hc.cacheMu.Lock()
+	defer hc.cacheMu.Unlock()
+	hc.cacheTTL = ttl
+	hc.cached = nil
+}
+
+// CheckHealth performs all health checks and returns the overall status.
+// Results are cached briefly so probe bursts don't re-ping dependencies.
 func (hc *HealthChecker) CheckHealth(ctx context.Context) HealthResponse {
+	hc.cacheMu.Lock()
+	if hc.cacheTTL > 0 && hc.cached != nil && time.Since(hc.cachedAt) < hc.cacheTTL {
+		cached := *hc.cached
+		hc.cacheMu.Unlock()
+		return cached
+	}
+	hc.cacheMu.Unlock()
+
+	response := hc.checkHealthUncached(ctx)
+
+	hc.cacheMu.Lock()
+	hc.cached = &response
+	hc.cachedAt = time.Now()
+	hc.cacheMu.Unlock()
+
+	return response
+}
+
+// checkHealthUncached runs every component check
+func (hc *HealthChecker) checkHealthUncached(ctx context.Context) HealthResponse {
 	components := make(map[string]ComponentHealth)
 	
 	// Check database health
@@ -70,9 +219,17 @@ func (hc *HealthChecker) CheckHealth(ctx context.Context) HealthResponse {
 	
 	// Check external services
 	components["sms_service"] = hc.checkSMSService(ctx)
-	
+
+	// Check the background SMS worker liveness
+	components["sms_worker"] = hc.checkSMSWorker(ctx)
+
+
 	// Determine overall status
 	overallStatus := hc.determineOverallStatus(components)
+
+	// Keep a rolling history for the status page
+	hc.recordHistory(components)
+
 	
 	return HealthResponse{
 		Status:     overallStatus,
@@ -118,19 +275,32 @@ func (hc *HealthChecker) checkDatabase(ctx context.Context) ComponentHealth {
 	details := map[string]interface{}{
 		"open_connections":     stats.OpenConnections,
 		"max_open_connections": stats.MaxOpenConnections,
-		"in_use":              stats.InUse,
-		"idle":                stats.Idle,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
 	}
-	
+
 	// Check if connections are healthy
 	status := StatusHealthy
 	message := "Database is healthy"
-	
+
 	if stats.OpenConnections > int(float64(stats.MaxOpenConnections)*0.8) {
 		status = StatusDegraded
 		message = "Database connection pool is nearly exhausted"
 	}
-	
+
+	// A ping succeeds against a read-only replica or a primary in failover,
+	// so additionally check writability and report degraded when read-only
+	var inRecovery bool
+	if err := hc.db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err == nil {
+		details["writable"] = !inRecovery
+		if wStatus, wMessage := databaseWritability(inRecovery); wStatus != StatusHealthy {
+			status = wStatus
+			message = wMessage
+		}
+	} else {
+		details["writable"] = "unknown"
+	}
+
 	return ComponentHealth{
 		Status:      status,
 		Message:     message,
@@ -140,6 +310,15 @@ func (hc *HealthChecker) checkDatabase(ctx context.Context) ComponentHealth {
 	}
 }
 
+// databaseWritability maps the pg_is_in_recovery() result to a component
+// status: a database in recovery serves reads but rejects writes
+func databaseWritability(inRecovery bool) (HealthStatus, string) {
+	if inRecovery {
+		return StatusDegraded, "Database is read-only (in recovery)"
+	}
+	return StatusHealthy, "Database is healthy"
+}
+
 // checkRedis checks the health of the Redis connection
 func (hc *HealthChecker) checkRedis(ctx context.Context) ComponentHealth {
 	start := time.Now()
@@ -180,12 +359,11 @@ func (hc *HealthChecker) checkRedis(ctx context.Context) ComponentHealth {
 	}
 	
 	// Get Redis info for detailed health
-	info, err := hc.redis.Info(ctx).Result()
 	details := map[string]interface{}{
 		"ping_response": pong,
 	}
-	
-	if err == nil {
+
+	if _, err := hc.redis.Info(ctx).Result(); err == nil {
 		details["info_available"] = true
 	}
 	
@@ -227,6 +405,85 @@ func (hc *HealthChecker) checkSMSService(ctx context.Context) ComponentHealth {
 	}
 }
 
+// redisHeartbeat reads the worker heartbeat straight from Redis, for
+// checkers constructed without a queue-backed heartbeat source. It
+// delegates to the Redis queue's own reader so the key name and format
+// live in one place.
+func (hc *HealthChecker) redisHeartbeat(ctx context.Context) (time.Time, error) {
+	if hc.redis == nil {
+		return time.Time{}, fmt.Errorf("redis connection not initialized")
+	}
+	return jobs.NewRedisJobQueue(hc.redis).LastHeartbeat(ctx)
+}
+
+// checkSMSWorker checks whether the background SMS worker is still alive
+// by reading the heartbeat the worker records on each loop iteration,
+// through the queue backend when one is wired. Even with the backend
+// healthy, the worker goroutine itself may have died.
+func (hc *HealthChecker) checkSMSWorker(ctx context.Context) ComponentHealth {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Prefer the queue backend's own view of the heartbeat; fall back to
+	// the raw Redis key when no backend has been wired (older callers)
+	var lastHeartbeat time.Time
+	var err error
+	if hc.heartbeatSource != nil {
+		lastHeartbeat, err = hc.heartbeatSource(ctx)
+	} else {
+		lastHeartbeat, err = hc.redisHeartbeat(ctx)
+	}
+	duration := time.Since(start)
+
+	if err != nil {
+		hc.logger.LogError(ctx, err, "SMS worker health check failed", nil)
+		return ComponentHealth{
+			Status:      StatusUnhealthy,
+			Message:     fmt.Sprintf("Failed to read worker heartbeat: %v", err),
+			LastChecked: time.Now(),
+			Duration:    duration.String(),
+		}
+	}
+
+	if lastHeartbeat.IsZero() {
+		// No heartbeat at all usually means the worker is intentionally
+		// disabled (e.g. no Redis); degrade rather than fail the service
+		return ComponentHealth{
+			Status:      StatusDegraded,
+			Message:     "SMS worker has never recorded a heartbeat",
+			LastChecked: time.Now(),
+			Duration:    duration.String(),
+		}
+	}
+
+	age := time.Since(lastHeartbeat)
+	details := map[string]interface{}{
+		"last_heartbeat": lastHeartbeat.UTC(),
+		"age":            age.String(),
+		"threshold":      hc.heartbeatThreshold.String(),
+	}
+
+	if age > hc.heartbeatThreshold {
+		return ComponentHealth{
+			Status:      StatusUnhealthy,
+			Message:     fmt.Sprintf("SMS worker heartbeat is stale (last seen %s ago)", age.Round(time.Second)),
+			LastChecked: time.Now(),
+			Duration:    duration.String(),
+			Details:     details,
+		}
+	}
+
+	return ComponentHealth{
+		Status:      StatusHealthy,
+		Message:     "SMS worker is alive",
+		LastChecked: time.Now(),
+		Duration:    duration.String(),
+		Details:     details,
+	}
+}
+
 // determineOverallStatus determines the overall system health based on component health
 func (hc *HealthChecker) determineOverallStatus(components map[string]ComponentHealth) HealthStatus {
 	hasUnhealthy := false
@@ -287,13 +544,14 @@ func (hc *HealthChecker) ReadinessHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		health := hc.CheckHealth(ctx)
-		
-		// Service is ready if it's healthy or degraded
-		if health.Status == StatusUnhealthy {
+
+		// Only hard dependencies gate readiness: a down SMS provider or
+		// worker degrades the service but it can still serve traffic
+		if hc.readinessStatus(health.Components) == StatusUnhealthy {
 			c.JSON(http.StatusServiceUnavailable, gin.H{
 				"status":    "not_ready",
 				"timestamp": time.Now(),
-				"reason":    "Service is unhealthy",
+				"reason":    "A hard dependency is unhealthy",
 			})
 			return
 		}
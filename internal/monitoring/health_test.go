@@ -0,0 +1,219 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend/internal/jobs"
+)
+
+func newTestHealthChecker(t *testing.T) (*HealthChecker, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewHealthChecker(nil, client, "test", NewLogger("test")), client
+}
+
+func TestCheckSMSWorker_FreshHeartbeatIsHealthy(t *testing.T) {
+	hc, client := newTestHealthChecker(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, jobs.WorkerHeartbeatKey, time.Now().Unix(), 0).Err())
+
+	health := hc.checkSMSWorker(ctx)
+	assert.Equal(t, StatusHealthy, health.Status)
+}
+
+func TestCheckSMSWorker_StaleHeartbeatIsUnhealthy(t *testing.T) {
+	hc, client := newTestHealthChecker(t)
+	ctx := context.Background()
+
+	stale := time.Now().Add(-10 * time.Minute).Unix()
+	require.NoError(t, client.Set(ctx, jobs.WorkerHeartbeatKey, stale, 0).Err())
+
+	health := hc.checkSMSWorker(ctx)
+	assert.Equal(t, StatusUnhealthy, health.Status)
+	assert.Contains(t, health.Message, "stale")
+}
+
+func TestCheckSMSWorker_MissingHeartbeatIsDegraded(t *testing.T) {
+	hc, _ := newTestHealthChecker(t)
+
+	// No heartbeat at all usually means the worker is intentionally off
+	// (no Redis); that degrades the service rather than failing it
+	health := hc.checkSMSWorker(context.Background())
+	assert.Equal(t, StatusDegraded, health.Status)
+	assert.Contains(t, health.Message, "never recorded")
+}
+
+func TestCheckSMSWorker_UsesConfiguredHeartbeatSource(t *testing.T) {
+	// No Redis at all: the wired source must be the only thing consulted
+	hc := NewHealthChecker(nil, nil, "test", NewLogger("test"))
+	ctx := context.Background()
+
+	heartbeat := time.Now()
+	hc.SetWorkerHeartbeatSource(func(ctx context.Context) (time.Time, error) {
+		return heartbeat, nil
+	})
+	assert.Equal(t, StatusHealthy, hc.checkSMSWorker(ctx).Status)
+
+	heartbeat = time.Now().Add(-10 * time.Minute)
+	health := hc.checkSMSWorker(ctx)
+	assert.Equal(t, StatusUnhealthy, health.Status)
+	assert.Contains(t, health.Message, "stale")
+
+	heartbeat = time.Time{}
+	health = hc.checkSMSWorker(ctx)
+	assert.Equal(t, StatusDegraded, health.Status)
+	assert.Contains(t, health.Message, "never recorded")
+
+	hc.SetWorkerHeartbeatSource(func(ctx context.Context) (time.Time, error) {
+		return time.Time{}, fmt.Errorf("backend unavailable")
+	})
+	assert.Equal(t, StatusUnhealthy, hc.checkSMSWorker(ctx).Status)
+}
+
+func TestComponentUptime(t *testing.T) {
+	assert.Equal(t, float64(0), componentUptime(nil))
+	assert.Equal(t, float64(100), componentUptime([]HealthStatus{StatusHealthy, StatusDegraded}))
+	assert.Equal(t, float64(50), componentUptime([]HealthStatus{StatusHealthy, StatusUnhealthy}))
+	assert.Equal(t, float64(75), componentUptime([]HealthStatus{
+		StatusHealthy, StatusHealthy, StatusUnhealthy, StatusDegraded,
+	}))
+}
+
+func TestHistoryHandler_ReportsUptime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hc, _ := newTestHealthChecker(t)
+
+	// Seed mixed results for one component
+	for _, status := range []HealthStatus{StatusHealthy, StatusHealthy, StatusUnhealthy, StatusHealthy} {
+		hc.recordHistory(map[string]ComponentHealth{"database": {Status: status}})
+	}
+
+	router := gin.New()
+	router.GET("/health/history", hc.HistoryHandler())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/history", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"database"`)
+	assert.Contains(t, w.Body.String(), `"uptime_percent":75`)
+	assert.Contains(t, w.Body.String(), `"checks":4`)
+}
+
+func TestRecordHistory_WindowIsBounded(t *testing.T) {
+	hc, _ := newTestHealthChecker(t)
+
+	for i := 0; i < healthHistorySize*2; i++ {
+		hc.recordHistory(map[string]ComponentHealth{"redis": {Status: StatusHealthy}})
+	}
+
+	hc.historyMu.Lock()
+	defer hc.historyMu.Unlock()
+	assert.Len(t, hc.history["redis"], healthHistorySize)
+}
+
+func TestReadinessStatus_IgnoresSoftComponents(t *testing.T) {
+	hc, _ := newTestHealthChecker(t)
+
+	// The SMS provider being down degrades but doesn't block readiness
+	components := map[string]ComponentHealth{
+		"database":    {Status: StatusHealthy},
+		"redis":       {Status: StatusHealthy},
+		"sms_service": {Status: StatusUnhealthy},
+		"sms_worker":  {Status: StatusUnhealthy},
+	}
+	assert.Equal(t, StatusHealthy, hc.readinessStatus(components))
+
+	// A hard dependency being down fails readiness
+	components["database"] = ComponentHealth{Status: StatusUnhealthy}
+	assert.Equal(t, StatusUnhealthy, hc.readinessStatus(components))
+}
+
+func TestReadinessHandler_FailsOnHardDependency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hc, _ := newTestHealthChecker(t)
+	hc.SetCacheTTL(0)
+
+	router := gin.New()
+	router.GET("/health/ready", hc.ReadinessHandler())
+
+	// db is nil in the test checker, so the database (hard) is unhealthy
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// If the database were soft too, readiness would pass despite it
+	hc.SetSoftComponents([]string{"database", "sms_service", "sms_worker"})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCheckHealth_CachesWithinTTL(t *testing.T) {
+	hc, _ := newTestHealthChecker(t)
+	hc.SetCacheTTL(time.Minute)
+	ctx := context.Background()
+
+	first := hc.CheckHealth(ctx)
+	second := hc.CheckHealth(ctx)
+
+	// The identical timestamp proves the second call reused the cached
+	// result instead of re-running the component checks
+	assert.Equal(t, first.Timestamp, second.Timestamp)
+}
+
+func TestCheckHealth_RefreshesAfterTTL(t *testing.T) {
+	hc, _ := newTestHealthChecker(t)
+	hc.SetCacheTTL(10 * time.Millisecond)
+	ctx := context.Background()
+
+	first := hc.CheckHealth(ctx)
+	time.Sleep(20 * time.Millisecond)
+	second := hc.CheckHealth(ctx)
+
+	assert.NotEqual(t, first.Timestamp, second.Timestamp)
+}
+
+func TestCheckHealth_CachingDisabled(t *testing.T) {
+	hc, _ := newTestHealthChecker(t)
+	hc.SetCacheTTL(0)
+	ctx := context.Background()
+
+	first := hc.CheckHealth(ctx)
+	second := hc.CheckHealth(ctx)
+	assert.NotEqual(t, first.Timestamp, second.Timestamp)
+}
+
+func TestDatabaseWritability(t *testing.T) {
+	status, message := databaseWritability(true)
+	assert.Equal(t, StatusDegraded, status)
+	assert.Contains(t, message, "read-only")
+
+	status, _ = databaseWritability(false)
+	assert.Equal(t, StatusHealthy, status)
+}
+
+func TestCheckSMSWorker_GarbageHeartbeatIsUnhealthy(t *testing.T) {
+	hc, client := newTestHealthChecker(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, jobs.WorkerHeartbeatKey, "not-a-timestamp", 0).Err())
+
+	health := hc.checkSMSWorker(ctx)
+	assert.Equal(t, StatusUnhealthy, health.Status)
+	assert.Contains(t, health.Message, fmt.Sprintf("%q", "not-a-timestamp"))
+}
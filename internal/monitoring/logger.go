@@ -2,9 +2,12 @@ package monitoring
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
+	"backend/pkg/utils"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -57,22 +60,29 @@ func NewLogger(environment string) *Logger {
 	}
 }
 
+// SetLevelByName changes the logger's level at runtime (e.g. "debug",
+// "info", "warn", "error"), validating against the known logrus levels
+func (l *Logger) SetLevelByName(name string) error {
+	level, err := logrus.ParseLevel(name)
+	if err != nil {
+		return fmt.Errorf("unknown log level %q", name)
+	}
+	l.SetLevel(level)
+	return nil
+}
+
 // WithContext adds context information to log entry
 func (l *Logger) WithContext(ctx context.Context) *logrus.Entry {
 	entry := l.Logger.WithContext(ctx)
 
-	// Add correlation ID if present
-	if correlationID := ctx.Value("correlation_id"); correlationID != nil {
+	// Request-scoped IDs travel through typed context keys (pkg/utils)
+	if correlationID := utils.CorrelationIDFromContext(ctx); correlationID != "" {
 		entry = entry.WithField("correlation_id", correlationID)
 	}
-
-	// Add user ID if present
-	if userID := ctx.Value("user_id"); userID != nil {
+	if userID := utils.UserIDFromContext(ctx); userID != "" {
 		entry = entry.WithField("user_id", userID)
 	}
-
-	// Add request ID if present
-	if requestID := ctx.Value("request_id"); requestID != nil {
+	if requestID := utils.RequestIDFromContext(ctx); requestID != "" {
 		entry = entry.WithField("request_id", requestID)
 	}
 
@@ -167,18 +167,32 @@ func NewMetrics() *Metrics {
 	return m
 }
 
+// unmatchedEndpointLabel is the endpoint label used for requests that hit
+// no registered route, so arbitrary probe URLs can't blow up cardinality
+const unmatchedEndpointLabel = "unmatched"
+
+// endpointLabel returns the templated route path (e.g. /api/v1/orders/:id)
+// for metrics labels, collapsing unmatched requests into a single label
+func endpointLabel(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+	return unmatchedEndpointLabel
+}
+
 // HTTPMiddleware creates a Gin middleware for HTTP metrics
 func (m *Metrics) HTTPMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		
+
 		c.Next()
-		
+
 		duration := time.Since(start).Seconds()
 		statusCode := strconv.Itoa(c.Writer.Status())
-		
-		httpRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), statusCode).Inc()
-		httpRequestDuration.WithLabelValues(c.Request.Method, c.FullPath()).Observe(duration)
+		endpoint := endpointLabel(c)
+
+		httpRequestsTotal.WithLabelValues(c.Request.Method, endpoint, statusCode).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, endpoint).Observe(duration)
 	}
 }
 
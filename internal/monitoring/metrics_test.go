@@ -0,0 +1,36 @@
+package monitoring
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMiddleware_NormalizesUnmatchedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := NewMetrics()
+
+	router := gin.New()
+	router.Use(metrics.HTTPMiddleware())
+	router.GET("/api/v1/customers/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// A matched route keeps its templated path as the label
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/customers/123", nil))
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		httpRequestsTotal.WithLabelValues(http.MethodGet, "/api/v1/customers/:id", "200")))
+
+	// Unknown paths all collapse into the single "unmatched" label
+	for _, path := range []string{"/nope", "/wp-admin.php", "/..%2f..%2fetc"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	assert.Equal(t, float64(3), testutil.ToFloat64(
+		httpRequestsTotal.WithLabelValues(http.MethodGet, unmatchedEndpointLabel, "404")))
+}
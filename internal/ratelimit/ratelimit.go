@@ -0,0 +1,87 @@
+// Package ratelimit holds Redis-backed business rate limits, distinct from
+// the per-IP HTTP limiter in pkg/middleware.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// orderRateKeyPrefix namespaces the per-customer order counters
+const orderRateKeyPrefix = "order_rate:"
+
+// PerCustomerOrders caps how many orders a customer can create within a
+// fixed window, backed by a Redis counter so the limit holds across
+// replicas
+type PerCustomerOrders struct {
+	client *redis.Client
+	limit  int
+	window time.Duration
+}
+
+// NewPerCustomerOrders allows limit orders per customer per window
+func NewPerCustomerOrders(client *redis.Client, limit int, window time.Duration) *PerCustomerOrders {
+	return &PerCustomerOrders{
+		client: client,
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Take consumes one creation slot for the customer and reports whether the
+// customer is still within the limit. Redis failures fail open so an
+// outage doesn't block order creation.
+func (l *PerCustomerOrders) Take(ctx context.Context, customerID uuid.UUID) (bool, error) {
+	if l.limit <= 0 {
+		return true, nil
+	}
+
+	key := orderRateKeyPrefix + customerID.String()
+	count, err := l.client.Incr(ctx, key).Result()
+	if err != nil {
+		return true, fmt.Errorf("failed to count customer orders: %w", err)
+	}
+	if count == 1 {
+		l.client.Expire(ctx, key, l.window)
+	}
+
+	return count <= int64(l.limit), nil
+}
+
+// submissionDedupPrefix namespaces duplicate-submission fingerprints
+const submissionDedupPrefix = "order_dedup:"
+
+// SubmissionDedup rejects identical order submissions within a short
+// window, protecting against double-click duplicates
+type SubmissionDedup struct {
+	client *redis.Client
+	window time.Duration
+}
+
+// NewSubmissionDedup creates a guard with the given window
+func NewSubmissionDedup(client *redis.Client, window time.Duration) *SubmissionDedup {
+	return &SubmissionDedup{client: client, window: window}
+}
+
+// Claim reports whether this fingerprint is the first submission within
+// the window. Redis failures fail open.
+func (d *SubmissionDedup) Claim(ctx context.Context, fingerprint string) (bool, error) {
+	claimed, err := d.client.SetNX(ctx, submissionDedupPrefix+fingerprint, 1, d.window).Result()
+	if err != nil {
+		return true, fmt.Errorf("failed to claim submission fingerprint: %w", err)
+	}
+	return claimed, nil
+}
+
+// Release frees a claimed fingerprint so a submission whose processing
+// failed can be retried immediately instead of bouncing as a duplicate
+func (d *SubmissionDedup) Release(ctx context.Context, fingerprint string) error {
+	if err := d.client.Del(ctx, submissionDedupPrefix+fingerprint).Err(); err != nil {
+		return fmt.Errorf("failed to release submission fingerprint: %w", err)
+	}
+	return nil
+}
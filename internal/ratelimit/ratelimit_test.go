@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func limiterFixture(t *testing.T, limit int, window time.Duration) (*PerCustomerOrders, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewPerCustomerOrders(client, limit, window), mr
+}
+
+func TestPerCustomerOrders_AllowsUpToLimit(t *testing.T) {
+	limiter, _ := limiterFixture(t, 3, time.Hour)
+	ctx := context.Background()
+	customerID := uuid.New()
+
+	for i := 0; i < 3; i++ {
+		allowed, err := limiter.Take(ctx, customerID)
+		require.NoError(t, err)
+		assert.True(t, allowed, "order %d should be within the limit", i+1)
+	}
+
+	allowed, err := limiter.Take(ctx, customerID)
+	require.NoError(t, err)
+	assert.False(t, allowed, "the fourth order must be rejected")
+
+	// A different customer has an independent budget
+	allowed, err = limiter.Take(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestPerCustomerOrders_WindowResets(t *testing.T) {
+	limiter, mr := limiterFixture(t, 1, time.Minute)
+	ctx := context.Background()
+	customerID := uuid.New()
+
+	allowed, err := limiter.Take(ctx, customerID)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = limiter.Take(ctx, customerID)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Once the window passes, the budget resets
+	mr.FastForward(2 * time.Minute)
+	allowed, err = limiter.Take(ctx, customerID)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestPerCustomerOrders_DisabledByZeroLimit(t *testing.T) {
+	limiter, _ := limiterFixture(t, 0, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		allowed, err := limiter.Take(context.Background(), uuid.New())
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+}
+
+func TestSubmissionDedup(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	guard := NewSubmissionDedup(client, time.Minute)
+	ctx := context.Background()
+
+	first, err := guard.Claim(ctx, "fingerprint-a")
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	// The same fingerprint within the window is a duplicate
+	first, err = guard.Claim(ctx, "fingerprint-a")
+	require.NoError(t, err)
+	assert.False(t, first)
+
+	// Different fingerprints are independent
+	first, err = guard.Claim(ctx, "fingerprint-b")
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	// A released fingerprint is immediately claimable again
+	require.NoError(t, guard.Release(ctx, "fingerprint-a"))
+	first, err = guard.Claim(ctx, "fingerprint-a")
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	// The window expires
+	mr.FastForward(2 * time.Minute)
+	first, err = guard.Claim(ctx, "fingerprint-a")
+	require.NoError(t, err)
+	assert.True(t, first)
+}
@@ -1,7 +1,13 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
 	"backend/pkg/models"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -14,33 +20,80 @@ func NewCustomerRepository(db *gorm.DB) *CustomerRepository {
 	return &CustomerRepository{db: db}
 }
 
-func (r *CustomerRepository) Create(customer *models.Customer) error {
-	return r.db.Create(customer).Error
+func (r *CustomerRepository) Create(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
+	// Stamp new rows with the caller's tenant
+	if customer.TenantID == "" {
+		customer.TenantID = tenantFor(ctx)
+	}
+	if err := r.db.WithContext(ctx).Create(customer).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
 }
 
-func (r *CustomerRepository) GetByID(id uuid.UUID) (*models.Customer, error) {
+func (r *CustomerRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
 	var customer models.Customer
-	err := r.db.Where("id = ? AND is_active = ?", id, true).First(&customer).Error
+	err := tenantScoped(ctx, r.db.WithContext(ctx)).Where("id = ? AND is_active = ?", id, true).First(&customer).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrCustomerNotFound
+		}
 		return nil, err
 	}
 	return &customer, nil
 }
 
-func (r *CustomerRepository) GetByCode(code string) (*models.Customer, error) {
+func (r *CustomerRepository) GetByCode(ctx context.Context, code string) (*models.Customer, error) {
 	var customer models.Customer
-	err := r.db.Where("code = ? AND is_active = ?", code, true).First(&customer).Error
+	err := tenantScoped(ctx, r.db.WithContext(ctx)).Where("code = ? AND is_active = ?", strings.ToUpper(code), true).First(&customer).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrCustomerNotFound
+		}
 		return nil, err
 	}
 	return &customer, nil
 }
 
-func (r *CustomerRepository) List(query *models.ListCustomersQuery) ([]models.Customer, int64, error) {
-	var customers []models.Customer
+// List returns a simple page of active customers without filters
+func (r *CustomerRepository) List(ctx context.Context, limit, offset int) ([]*models.Customer, error) {
+	// An empty result is not an error; start with a non-nil slice so
+	// callers serialize [] rather than null
+	customers := []*models.Customer{}
+	db := tenantScoped(ctx, r.db.WithContext(ctx)).Where("is_active = ?", true)
+
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	err := db.Order("created_at DESC, id DESC").Find(&customers).Error
+	return customers, err
+}
+
+// ListAfter returns up to limit active customers with IDs greater than
+// afterID, ordered by ID. This keyset pagination stays stable across
+// concurrent inserts, unlike OFFSET, making it suitable for resumable
+// exports.
+func (r *CustomerRepository) ListAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]models.Customer, error) {
+	customers := []models.Customer{}
+	err := tenantScoped(ctx, r.db.WithContext(ctx)).
+		Where("is_active = ? AND id > ?", true, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&customers).Error
+	return customers, err
+}
+
+// Search returns a filtered page of active customers along with the total
+// number of matches
+func (r *CustomerRepository) Search(ctx context.Context, query *models.ListCustomersQuery) ([]models.Customer, int64, bool, error) {
+	customers := []models.Customer{}
 	var total int64
 
-	db := r.db.Model(&models.Customer{}).Where("is_active = ?", true)
+	db := tenantScoped(ctx, r.db.WithContext(ctx).Model(&models.Customer{})).Where("is_active = ?", true)
 
 	// Apply filters
 	if query.Code != "" {
@@ -53,34 +106,118 @@ func (r *CustomerRepository) List(query *models.ListCustomersQuery) ([]models.Cu
 		db = db.Where("phone ILIKE ?", "%"+query.Phone+"%")
 	}
 
-	// Get total count
-	if err := db.Count(&total).Error; err != nil {
-		return nil, 0, err
+	// Skippable COUNT(*): see OrderRepository.List
+	if query.Count {
+		if err := db.Count(&total).Error; err != nil {
+			return nil, 0, false, err
+		}
 	}
 
-	// Apply pagination
-	if query.Limit > 0 {
-		db = db.Limit(query.Limit)
+	// Apply pagination (one extra row detects further pages when the
+	// count is skipped)
+	fetchLimit := query.Limit
+	if !query.Count && fetchLimit > 0 {
+		fetchLimit++
+	}
+	if fetchLimit > 0 {
+		db = db.Limit(fetchLimit)
 	}
 	if query.Offset > 0 {
 		db = db.Offset(query.Offset)
 	}
 
-	err := db.Order("created_at DESC").Find(&customers).Error
-	return customers, total, err
+	err := db.Order("created_at DESC, id DESC").Find(&customers).Error
+
+	hasMore := false
+	if !query.Count && query.Limit > 0 && len(customers) > query.Limit {
+		hasMore = true
+		customers = customers[:query.Limit]
+	}
+
+	return customers, total, hasMore, err
+}
+
+// Update applies the non-empty fields of customer to the stored row and
+// returns the updated customer
+func (r *CustomerRepository) Update(ctx context.Context, id uuid.UUID, customer *models.Customer) (*models.Customer, error) {
+	existing, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// A customer's code never changes after creation
+	if customer.Code != "" && customer.Code != existing.Code {
+		return nil, fmt.Errorf("%w: code", models.ErrImmutableField)
+	}
+
+	if customer.Name != "" {
+		existing.Name = customer.Name
+	}
+	if customer.Phone != "" {
+		existing.Phone = customer.Phone
+	}
+	if customer.Email != "" {
+		existing.Email = customer.Email
+	}
+	if customer.Locale != "" {
+		existing.Locale = customer.Locale
+	}
+
+	if err := r.db.WithContext(ctx).Save(existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
 }
 
-func (r *CustomerRepository) Update(customer *models.Customer) error {
-	return r.db.Save(customer).Error
+// Save persists the customer row exactly as given (unlike Update, empty
+// fields overwrite stored values)
+func (r *CustomerRepository) Save(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
+	if err := r.db.WithContext(ctx).Save(customer).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
 }
 
-func (r *CustomerRepository) Delete(id uuid.UUID) error {
+func (r *CustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Soft delete by setting is_active = false
-	return r.db.Model(&models.Customer{}).Where("id = ?", id).Update("is_active", false).Error
+	result := tenantScoped(ctx, r.db.WithContext(ctx).Model(&models.Customer{})).
+		Where("id = ? AND is_active = ?", id, true).
+		Update("is_active", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrCustomerNotFound
+	}
+	return nil
 }
 
-func (r *CustomerRepository) Exists(code string) (bool, error) {
+func (r *CustomerRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Customer{}).Where("code = ? AND is_active = ?", code, true).Count(&count).Error
+	err := tenantScoped(ctx, r.db.WithContext(ctx).Model(&models.Customer{})).Where("is_active = ?", true).Count(&count).Error
+	return count, err
+}
+
+// ExistsByID is the lightweight existence probe behind HEAD requests
+func (r *CustomerRepository) ExistsByID(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := tenantScoped(ctx, r.db.WithContext(ctx).Model(&models.Customer{})).
+		Where("id = ? AND is_active = ?", id, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsByPhone backs the flag-gated phone-uniqueness check on create
+func (r *CustomerRepository) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
+	var count int64
+	err := tenantScoped(ctx, r.db.WithContext(ctx).Model(&models.Customer{})).
+		Where("phone = ? AND is_active = ?", phone, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *CustomerRepository) Exists(ctx context.Context, code string) (bool, error) {
+	var count int64
+	err := tenantScoped(ctx, r.db.WithContext(ctx).Model(&models.Customer{})).Where("code = ? AND is_active = ?", strings.ToUpper(code), true).Count(&count).Error
 	return count > 0, err
-}
\ No newline at end of file
+}
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// pgUniqueViolationCode is the Postgres error code for unique constraint violations
+const pgUniqueViolationCode = "23505"
+
+// IsUniqueViolation reports whether err was caused by a violated unique
+// constraint (e.g. two concurrent inserts with the same customer code).
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgUniqueViolationCode
+	}
+
+	return false
+}
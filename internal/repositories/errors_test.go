@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	uniqueErr := &pgconn.PgError{
+		Code:           "23505",
+		ConstraintName: "idx_customers_code",
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "postgres unique violation",
+			err:      uniqueErr,
+			expected: true,
+		},
+		{
+			name:     "wrapped postgres unique violation",
+			err:      fmt.Errorf("failed to create customer: %w", uniqueErr),
+			expected: true,
+		},
+		{
+			name:     "gorm duplicated key sentinel",
+			err:      gorm.ErrDuplicatedKey,
+			expected: true,
+		},
+		{
+			name:     "other postgres error",
+			err:      &pgconn.PgError{Code: "23503"}, // foreign key violation
+			expected: false,
+		},
+		{
+			name:     "generic error",
+			err:      errors.New("connection refused"),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsUniqueViolation(tt.err))
+		})
+	}
+}
@@ -6,60 +6,98 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 
-	"savannah-backend/internal/domain"
+	"backend/pkg/models"
 )
 
-// CustomerRepository is a mock implementation of the CustomerRepository interface
+// CustomerRepository is a mock implementation of the services.CustomerStore interface
 type CustomerRepository struct {
 	mock.Mock
 }
 
 // Create mocks the Create method
-func (m *CustomerRepository) Create(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
+func (m *CustomerRepository) Create(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
 	args := m.Called(ctx, customer)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.Customer), args.Error(1)
+	return args.Get(0).(*models.Customer), args.Error(1)
 }
 
 // GetByID mocks the GetByID method
-func (m *CustomerRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+func (m *CustomerRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.Customer), args.Error(1)
+	return args.Get(0).(*models.Customer), args.Error(1)
 }
 
 // GetByCode mocks the GetByCode method
-func (m *CustomerRepository) GetByCode(ctx context.Context, code string) (*domain.Customer, error) {
+func (m *CustomerRepository) GetByCode(ctx context.Context, code string) (*models.Customer, error) {
 	args := m.Called(ctx, code)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.Customer), args.Error(1)
+	return args.Get(0).(*models.Customer), args.Error(1)
 }
 
 // List mocks the List method
-func (m *CustomerRepository) List(ctx context.Context, limit, offset int) ([]*domain.Customer, error) {
+func (m *CustomerRepository) List(ctx context.Context, limit, offset int) ([]*models.Customer, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*domain.Customer), args.Error(1)
+	return args.Get(0).([]*models.Customer), args.Error(1)
+}
+
+// ListAfter mocks the ListAfter method
+func (m *CustomerRepository) ListAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]models.Customer, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Customer), args.Error(1)
+}
+
+// Search mocks the Search method
+func (m *CustomerRepository) Search(ctx context.Context, query *models.ListCustomersQuery) ([]models.Customer, int64, bool, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Bool(2), args.Error(3)
+	}
+	return args.Get(0).([]models.Customer), args.Get(1).(int64), args.Bool(2), args.Error(3)
 }
 
 // Update mocks the Update method
-func (m *CustomerRepository) Update(ctx context.Context, id uuid.UUID, customer *domain.Customer) (*domain.Customer, error) {
+func (m *CustomerRepository) Update(ctx context.Context, id uuid.UUID, customer *models.Customer) (*models.Customer, error) {
 	args := m.Called(ctx, id, customer)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.Customer), args.Error(1)
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+// Save mocks the Save method
+func (m *CustomerRepository) Save(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
+	args := m.Called(ctx, customer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Customer), args.Error(1)
+}
+
+// ExistsByID mocks the ExistsByID method
+func (m *CustomerRepository) ExistsByID(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
 }
 
 // Delete mocks the Delete method
+func (m *CustomerRepository) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
+	args := m.Called(ctx, phone)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *CustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -69,4 +107,4 @@ func (m *CustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
 func (m *CustomerRepository) Count(ctx context.Context) (int64, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
-}
\ No newline at end of file
+}
@@ -0,0 +1,112 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"backend/pkg/models"
+)
+
+// OrderRepository is a mock implementation of the services.OrderStore interface
+type OrderRepository struct {
+	mock.Mock
+}
+
+// Create mocks the Create method
+func (m *OrderRepository) Create(ctx context.Context, order *models.Order) (*models.Order, error) {
+	args := m.Called(ctx, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+// GetByID mocks the GetByID method
+func (m *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+// List mocks the List method
+func (m *OrderRepository) List(ctx context.Context, query *models.ListOrdersQuery) ([]models.OrderListItem, int64, bool, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Bool(2), args.Error(3)
+	}
+	return args.Get(0).([]models.OrderListItem), args.Get(1).(int64), args.Bool(2), args.Error(3)
+}
+
+// Recent mocks the Recent method
+func (m *OrderRepository) Recent(ctx context.Context, limit int) ([]models.Order, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Order), args.Error(1)
+}
+
+// GetByCustomerID mocks the GetByCustomerID method
+func (m *OrderRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID) ([]models.Order, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Order), args.Error(1)
+}
+
+// Update mocks the Update method
+func (m *OrderRepository) Update(ctx context.Context, order *models.Order) (*models.Order, error) {
+	args := m.Called(ctx, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+// UpdateStatus mocks the UpdateStatus method
+func (m *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.OrderStatus, smsSentAt *time.Time) error {
+	args := m.Called(ctx, id, status, smsSentAt)
+	return args.Error(0)
+}
+
+// MarkSMSSent mocks the MarkSMSSent method
+func (m *OrderRepository) MarkSMSSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	args := m.Called(ctx, id, sentAt)
+	return args.Error(0)
+}
+
+// ExistsByID mocks the ExistsByID method
+func (m *OrderRepository) ExistsByID(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// Delete mocks the Delete method
+func (m *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// GetUnnotifiedOrdersBetween mocks the GetUnnotifiedOrdersBetween method
+func (m *OrderRepository) GetUnnotifiedOrdersBetween(ctx context.Context, from, to time.Time) ([]models.Order, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Order), args.Error(1)
+}
+
+// GetPendingSMSOrders mocks the GetPendingSMSOrders method
+func (m *OrderRepository) GetPendingSMSOrders(ctx context.Context) ([]models.Order, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Order), args.Error(1)
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+
+	"backend/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderEventRepository persists the append-only order lifecycle log
+type OrderEventRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderEventRepository(db *gorm.DB) *OrderEventRepository {
+	return &OrderEventRepository{db: db}
+}
+
+// Append records one lifecycle event
+func (r *OrderEventRepository) Append(ctx context.Context, event *models.OrderEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// ListByOrder returns an order's events in chronological order
+func (r *OrderEventRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]models.OrderEvent, error) {
+	events := []models.OrderEvent{}
+	err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at, id").
+		Find(&events).Error
+	return events, err
+}
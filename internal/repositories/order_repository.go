@@ -1,9 +1,12 @@
 package repositories
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"backend/pkg/models"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -16,80 +19,193 @@ func NewOrderRepository(db *gorm.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-func (r *OrderRepository) Create(order *models.Order) error {
-	return r.db.Create(order).Error
+func (r *OrderRepository) Create(ctx context.Context, order *models.Order) (*models.Order, error) {
+	// Stamp new rows with the caller's tenant
+	if order.TenantID == "" {
+		order.TenantID = tenantFor(ctx)
+	}
+	if err := r.db.WithContext(ctx).Create(order).Error; err != nil {
+		return nil, err
+	}
+	return order, nil
 }
 
-func (r *OrderRepository) GetByID(id uuid.UUID) (*models.Order, error) {
+func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	var order models.Order
-	err := r.db.Preload("Customer").Where("id = ? AND is_active = ?", id, true).First(&order).Error
+	err := tenantScoped(ctx, r.db.WithContext(ctx)).Preload("Customer").Preload("Items").Where("id = ? AND is_active = ?", id, true).First(&order).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrOrderNotFound
+		}
 		return nil, err
 	}
 	return &order, nil
 }
 
-func (r *OrderRepository) List(query *models.ListOrdersQuery) ([]models.Order, int64, error) {
-	var orders []models.Order
+// List returns a lightweight projection joining only the customer's name,
+// keeping full customer rows (and their PII) out of list responses. The
+// single-order GetByID keeps the full preload.
+func (r *OrderRepository) List(ctx context.Context, query *models.ListOrdersQuery) ([]models.OrderListItem, int64, bool, error) {
+	// An empty result is not an error; start with a non-nil slice so
+	// callers serialize [] rather than null
+	items := []models.OrderListItem{}
 	var total int64
 
-	db := r.db.Model(&models.Order{}).Where("is_active = ?", true)
+	db := r.db.WithContext(ctx).Model(&models.Order{}).Where("orders.is_active = ?", true)
+
+	// The join makes the bare tenant filter ambiguous, so qualify it here
+	// rather than using tenantScoped
+	if tenant := tenantFor(ctx); tenant != "" {
+		db = db.Where("orders.tenant_id = ?", tenant)
+	}
 
 	// Apply filters
 	if query.CustomerID != uuid.Nil {
-		db = db.Where("customer_id = ?", query.CustomerID)
+		db = db.Where("orders.customer_id = ?", query.CustomerID)
 	}
 	if query.Status != "" {
-		db = db.Where("status = ?", query.Status)
+		db = db.Where("orders.status = ?", query.Status)
 	}
 
-	// Get total count
-	if err := db.Count(&total).Error; err != nil {
-		return nil, 0, err
+	// The COUNT(*) is expensive on large filtered sets and skippable when
+	// the client only needs to know whether a next page exists
+	if query.Count {
+		if err := db.Count(&total).Error; err != nil {
+			return nil, 0, false, err
+		}
 	}
 
-	// Apply pagination
-	if query.Limit > 0 {
-		db = db.Limit(query.Limit)
+	// Apply pagination; without a count we fetch one extra row to learn
+	// whether more pages exist
+	fetchLimit := query.Limit
+	if !query.Count && fetchLimit > 0 {
+		fetchLimit++
+	}
+	if fetchLimit > 0 {
+		db = db.Limit(fetchLimit)
 	}
 	if query.Offset > 0 {
 		db = db.Offset(query.Offset)
 	}
 
-	err := db.Preload("Customer").Order("ordered_at DESC").Find(&orders).Error
-	return orders, total, err
+	err := db.
+		Select("orders.id, orders.customer_id, customers.name AS customer_name, orders.item, orders.amount, orders.ordered_at, orders.status, orders.sms_sent_at, orders.created_at").
+		Joins("JOIN customers ON customers.id = orders.customer_id").
+		Order("orders.ordered_at DESC, orders.id DESC").
+		Scan(&items).Error
+
+	hasMore := false
+	if !query.Count && query.Limit > 0 && len(items) > query.Limit {
+		hasMore = true
+		items = items[:query.Limit]
+	}
+
+	return items, total, hasMore, err
+}
+
+// Recent returns the most recently placed active orders with their
+// customers preloaded, for dashboard-style feeds
+func (r *OrderRepository) Recent(ctx context.Context, limit int) ([]models.Order, error) {
+	orders := []models.Order{}
+	err := tenantScoped(ctx, r.db.WithContext(ctx)).Preload("Customer").
+		Where("is_active = ?", true).
+		Order("ordered_at DESC, id DESC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
 }
 
-func (r *OrderRepository) GetByCustomerID(customerID uuid.UUID) ([]models.Order, error) {
-	var orders []models.Order
-	err := r.db.Where("customer_id = ? AND is_active = ?", customerID, true).
-		Order("ordered_at DESC").Find(&orders).Error
+func (r *OrderRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID) ([]models.Order, error) {
+	orders := []models.Order{}
+	err := tenantScoped(ctx, r.db.WithContext(ctx)).Where("customer_id = ? AND is_active = ?", customerID, true).
+		Order("ordered_at DESC, id DESC").Find(&orders).Error
 	return orders, err
 }
 
-func (r *OrderRepository) Update(order *models.Order) error {
-	return r.db.Save(order).Error
+func (r *OrderRepository) Update(ctx context.Context, order *models.Order) (*models.Order, error) {
+	if err := r.db.WithContext(ctx).Save(order).Error; err != nil {
+		return nil, err
+	}
+	return order, nil
 }
 
-func (r *OrderRepository) UpdateStatus(id uuid.UUID, status string, smsSentAt *time.Time) error {
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.OrderStatus, smsSentAt *time.Time) error {
 	updates := map[string]interface{}{
 		"status": status,
 	}
 	if smsSentAt != nil {
 		updates["sms_sent_at"] = *smsSentAt
 	}
-	return r.db.Model(&models.Order{}).Where("id = ?", id).Updates(updates).Error
+
+	result := r.db.WithContext(ctx).Model(&models.Order{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrOrderNotFound
+	}
+	return nil
+}
+
+// ExistsByID is the lightweight existence probe behind HEAD requests
+func (r *OrderRepository) ExistsByID(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := tenantScoped(ctx, r.db.WithContext(ctx).Model(&models.Order{})).
+		Where("id = ? AND is_active = ?", id, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MarkSMSSent stamps the order's sms_sent_at without touching its status
+// (the worker doesn't know what state the order has reached meanwhile)
+func (r *OrderRepository) MarkSMSSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("id = ?", id).
+		Update("sms_sent_at", sentAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrOrderNotFound
+	}
+	return nil
 }
 
-func (r *OrderRepository) Delete(id uuid.UUID) error {
+func (r *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Soft delete by setting is_active = false
-	return r.db.Model(&models.Order{}).Where("id = ?", id).Update("is_active", false).Error
+	result := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("id = ? AND is_active = ?", id, true).
+		Update("is_active", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrOrderNotFound
+	}
+	return nil
+}
+
+// GetUnnotifiedOrdersBetween returns active orders placed in [from, to]
+// whose confirmation SMS never went out, for admin backfills. Only
+// statuses where a confirmation SMS is appropriate are included: cancelled
+// orders and needs_review orders (whose notification was deliberately
+// suppressed) must not be texted by a backfill.
+func (r *OrderRepository) GetUnnotifiedOrdersBetween(ctx context.Context, from, to time.Time) ([]models.Order, error) {
+	orders := []models.Order{}
+	notifiableStatuses := []models.OrderStatus{models.OrderStatusPending, models.OrderStatusConfirmed}
+
+	err := tenantScoped(ctx, r.db.WithContext(ctx)).Preload("Customer").
+		Where("sms_sent_at IS NULL AND is_active = ? AND status IN ? AND ordered_at BETWEEN ? AND ?",
+			true, notifiableStatuses, from, to).
+		Order("ordered_at, id").
+		Find(&orders).Error
+	return orders, err
 }
 
-func (r *OrderRepository) GetPendingSMSOrders() ([]models.Order, error) {
-	var orders []models.Order
-	err := r.db.Preload("Customer").
-		Where("status = ? AND sms_sent_at IS NULL AND is_active = ?", "pending", true).
+func (r *OrderRepository) GetPendingSMSOrders(ctx context.Context) ([]models.Order, error) {
+	orders := []models.Order{}
+	err := r.db.WithContext(ctx).Preload("Customer").
+		Where("status = ? AND sms_sent_at IS NULL AND is_active = ?", models.OrderStatusPending, true).
 		Find(&orders).Error
 	return orders, err
-}
\ No newline at end of file
+}
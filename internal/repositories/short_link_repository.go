@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"backend/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// shortCodeAlphabet avoids visually ambiguous characters (0/O, 1/I/l)
+const shortCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// shortCodeLength gives ~40 bits of entropy, plenty for collision resistance
+const shortCodeLength = 8
+
+type ShortLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewShortLinkRepository(db *gorm.DB) *ShortLinkRepository {
+	return &ShortLinkRepository{db: db}
+}
+
+// generateShortCode returns a random code from the unambiguous alphabet
+func generateShortCode() (string, error) {
+	code := make([]byte, shortCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(shortCodeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate short code: %w", err)
+		}
+		code[i] = shortCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// GetOrCreate returns the order's short link, creating one if none exists.
+// Code collisions are vanishingly rare but retried anyway.
+func (r *ShortLinkRepository) GetOrCreate(ctx context.Context, orderID uuid.UUID) (*models.OrderShortLink, error) {
+	var existing models.OrderShortLink
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := generateShortCode()
+		if err != nil {
+			return nil, err
+		}
+
+		link := &models.OrderShortLink{Code: code, OrderID: orderID}
+		if err := r.db.WithContext(ctx).Create(link).Error; err != nil {
+			if IsUniqueViolation(err) {
+				continue
+			}
+			return nil, err
+		}
+		return link, nil
+	}
+
+	return nil, fmt.Errorf("failed to allocate a unique short code for order %s", orderID)
+}
+
+// GetByCode resolves a short code to its link record
+func (r *ShortLinkRepository) GetByCode(ctx context.Context, code string) (*models.OrderShortLink, error) {
+	var link models.OrderShortLink
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &link, nil
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateShortCode(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		code, err := generateShortCode()
+		require.NoError(t, err)
+
+		assert.Len(t, code, shortCodeLength)
+		for _, r := range code {
+			assert.True(t, strings.ContainsRune(shortCodeAlphabet, r),
+				"code %q contains character outside the alphabet", code)
+		}
+		seen[code] = true
+	}
+
+	// With 40 bits of entropy, 100 draws should never collide
+	assert.Len(t, seen, 100)
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"backend/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SMSJobRepository persists final SMS job states to Postgres so delivery
+// history outlives the Redis job data TTL
+type SMSJobRepository struct {
+	db *gorm.DB
+}
+
+func NewSMSJobRepository(db *gorm.DB) *SMSJobRepository {
+	return &SMSJobRepository{db: db}
+}
+
+// Record upserts a job's final state (a retried job may be recorded more
+// than once; the last write wins)
+func (r *SMSJobRepository) Record(ctx context.Context, record *models.SMSJobRecord) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			UpdateAll: true,
+		}).
+		Create(record).Error
+}
+
+// UpdateDeliveryStatus sets the recorded job's final delivery status
+// without touching its other columns. A missing record (job never
+// persisted) is not an error.
+func (r *SMSJobRepository) UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status string) error {
+	return r.db.WithContext(ctx).Model(&models.SMSJobRecord{}).
+		Where("id = ?", id).
+		Update("status", status).Error
+}
+
+// GetByID fetches a recorded job
+func (r *SMSJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SMSJobRecord, error) {
+	var record models.SMSJobRecord
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// List returns recorded jobs, optionally filtered by status, newest first
+func (r *SMSJobRepository) List(ctx context.Context, status string, limit, offset int) ([]models.SMSJobRecord, int64, error) {
+	var records []models.SMSJobRecord
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.SMSJobRecord{})
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+
+	err := db.Order("created_at DESC, id DESC").Find(&records).Error
+	return records, total, err
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+
+	"backend/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// tenantScoped narrows a query to the caller's tenant when the request
+// carries one. Rows from other tenants then simply don't match, so
+// cross-tenant lookups surface as not-found rather than leaking data.
+// Requests without a tenant (single-tenant deployments) are unaffected.
+func tenantScoped(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tenant := utils.TenantFromContext(ctx); tenant != "" {
+		return db.Where("tenant_id = ?", tenant)
+	}
+	return db
+}
+
+// tenantFor returns the tenant ID new rows should be stamped with
+func tenantFor(ctx context.Context) string {
+	return utils.TenantFromContext(ctx)
+}
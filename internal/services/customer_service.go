@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"backend/internal/repositories"
+	"backend/pkg/config"
+	"backend/pkg/models"
+	"backend/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// CustomerStore is the repository interface the customer service depends
+// on, so business rules can be unit-tested against a mock without a DB
+type CustomerStore interface {
+	Create(ctx context.Context, customer *models.Customer) (*models.Customer, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Customer, error)
+	GetByCode(ctx context.Context, code string) (*models.Customer, error)
+	List(ctx context.Context, limit, offset int) ([]*models.Customer, error)
+	ListAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]models.Customer, error)
+	Search(ctx context.Context, query *models.ListCustomersQuery) ([]models.Customer, int64, bool, error)
+	Update(ctx context.Context, id uuid.UUID, customer *models.Customer) (*models.Customer, error)
+	Save(ctx context.Context, customer *models.Customer) (*models.Customer, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Count(ctx context.Context) (int64, error)
+	ExistsByID(ctx context.Context, id uuid.UUID) (bool, error)
+	ExistsByPhone(ctx context.Context, phone string) (bool, error)
+}
+
+// CustomerService holds customer business rules, keeping HTTP concerns in
+// the handlers and persistence in the repository
+type CustomerService struct {
+	repo CustomerStore
+	// exportRepo serves analytics/export reads, optionally backed by a
+	// read replica; nil falls back to repo
+	exportRepo CustomerStore
+	// autoGenerateCodes lets CreateCustomer assign a code when the client
+	// omits one
+	autoGenerateCodes bool
+	codePrefix        string
+	// features gates optional behaviors like phone uniqueness; nil leaves
+	// them all off
+	features *config.FeatureFlags
+}
+
+// NewCustomerService creates a new customer service
+func NewCustomerService(repo CustomerStore) *CustomerService {
+	return &CustomerService{repo: repo}
+}
+
+// validateCustomer checks the business-level invariants of a customer
+func validateCustomer(customer *models.Customer) error {
+	if customer.Name == "" {
+		return fmt.Errorf("%w: name is required", models.ErrValidation)
+	}
+	if customer.Code == "" {
+		return fmt.Errorf("%w: code is required", models.ErrValidation)
+	}
+	// Phone is optional, but the customer must be reachable on some channel
+	if customer.Phone == "" && customer.Email == "" {
+		return fmt.Errorf("%w: a phone number or email address is required", models.ErrValidation)
+	}
+	return nil
+}
+
+// SetFeatureFlags wires runtime feature toggles
+func (s *CustomerService) SetFeatureFlags(features *config.FeatureFlags) {
+	s.features = features
+}
+
+// SetExportStore points export reads at an alternate store (e.g. a
+// repository on the read replica)
+func (s *CustomerService) SetExportStore(store CustomerStore) {
+	s.exportRepo = store
+}
+
+// exportStore returns the store export reads should use
+func (s *CustomerService) exportStore() CustomerStore {
+	if s.exportRepo != nil {
+		return s.exportRepo
+	}
+	return s.repo
+}
+
+// SetCodeGeneration enables server-assigned customer codes with the given
+// prefix
+func (s *CustomerService) SetCodeGeneration(enabled bool, prefix string) {
+	s.autoGenerateCodes = enabled
+	s.codePrefix = prefix
+}
+
+// codeAlphabet is the character set for generated customer codes
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// generateCustomerCode returns a collision-resistant code like CUST7K2M9XQA
+func generateCustomerCode(prefix string) (string, error) {
+	suffix := make([]byte, 8)
+	for i := range suffix {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate customer code: %w", err)
+		}
+		suffix[i] = codeAlphabet[n.Int64()]
+	}
+	return prefix + string(suffix), nil
+}
+
+// CreateCustomer validates and persists a new customer. With code
+// generation enabled and no code supplied, the server assigns one,
+// retrying on the (rare) unique-violation collision.
+func (s *CustomerService) CreateCustomer(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
+	// Codes are canonically uppercase; normalizing on write pairs with the
+	// upper(code) unique index to stop cust001/CUST001 coexisting
+	customer.Code = strings.ToUpper(customer.Code)
+
+	generated := customer.Code == "" && s.autoGenerateCodes
+
+	if generated {
+		code, err := generateCustomerCode(s.codePrefix)
+		if err != nil {
+			return nil, err
+		}
+		customer.Code = code
+	}
+
+	if err := validateCustomer(customer); err != nil {
+		return nil, err
+	}
+
+	// Phone uniqueness is flag-gated: existing deployments have duplicate
+	// numbers (shared family phones) and opt in per environment. The check
+	// is best-effort (no unique index backs it, since the flag can be off),
+	// so two truly concurrent creates can both pass; it exists to catch the
+	// common accidental double-registration, not to be a hard constraint.
+	if customer.Phone != "" && s.features != nil && s.features.Enabled(ctx, config.FeaturePhoneUniqueness) {
+		taken, err := s.repo.ExistsByPhone(ctx, customer.Phone)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, fmt.Errorf("%w: phone number is already in use", models.ErrValidation)
+		}
+	}
+
+	created, err := s.repo.Create(ctx, customer)
+	if !generated {
+		return created, err
+	}
+
+	// Regenerate on collision; anything else is a real error
+	for attempt := 0; attempt < 4 && repositories.IsUniqueViolation(err); attempt++ {
+		code, genErr := generateCustomerCode(s.codePrefix)
+		if genErr != nil {
+			return nil, genErr
+		}
+		customer.Code = code
+		created, err = s.repo.Create(ctx, customer)
+	}
+
+	return created, err
+}
+
+// GetCustomerByID returns a single active customer
+func (s *CustomerService) GetCustomerByID(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListCustomers returns a page of active customers
+func (s *CustomerService) ListCustomers(ctx context.Context, limit, offset int) ([]*models.Customer, error) {
+	return s.repo.List(ctx, limit, offset)
+}
+
+// SearchCustomers returns a filtered page of active customers, with
+// either the total match count or a has-more flag depending on query.Count
+func (s *CustomerService) SearchCustomers(ctx context.Context, query *models.ListCustomersQuery) ([]models.Customer, int64, bool, error) {
+	return s.repo.Search(ctx, query)
+}
+
+// ExportCustomers returns one resumable export chunk starting after the
+// given cursor, along with the cursor for the next chunk (empty when the
+// export is complete)
+func (s *CustomerService) ExportCustomers(ctx context.Context, cursor string, limit int) ([]models.Customer, string, error) {
+	afterID, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", models.ErrValidation, err)
+	}
+
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+
+	customers, err := s.exportStore().ListAfter(ctx, afterID, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(customers) == limit {
+		nextCursor = utils.EncodeCursor(customers[len(customers)-1].ID)
+	}
+	return customers, nextCursor, nil
+}
+
+// UpdateCustomer applies the non-empty fields of customer to the stored row
+func (s *CustomerService) UpdateCustomer(ctx context.Context, id uuid.UUID, customer *models.Customer) (*models.Customer, error) {
+	return s.repo.Update(ctx, id, customer)
+}
+
+// SaveCustomer persists a fully-specified customer row (used by PATCH,
+// where empty values are meaningful). The business invariants are
+// re-checked so a patch can't clear both notification channels.
+func (s *CustomerService) SaveCustomer(ctx context.Context, customer *models.Customer) (*models.Customer, error) {
+	if err := validateCustomer(customer); err != nil {
+		return nil, err
+	}
+	return s.repo.Save(ctx, customer)
+}
+
+// CustomerExists reports whether an active customer exists, without
+// fetching the row
+func (s *CustomerService) CustomerExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.repo.ExistsByID(ctx, id)
+}
+
+// DeleteCustomer soft-deletes a customer
+func (s *CustomerService) DeleteCustomer(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -6,19 +6,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
-	"savannah-backend/internal/domain"
-	"savannah-backend/internal/repositories/mocks"
+	"backend/internal/repositories/mocks"
+	"backend/pkg/config"
+	"backend/pkg/models"
 )
 
 type CustomerServiceTestSuite struct {
 	suite.Suite
-	service    *CustomerService
-	mockRepo   *mocks.CustomerRepository
-	ctx        context.Context
+	service  *CustomerService
+	mockRepo *mocks.CustomerRepository
+	ctx      context.Context
 }
 
 func (suite *CustomerServiceTestSuite) SetupTest() {
@@ -33,21 +35,21 @@ func (suite *CustomerServiceTestSuite) TearDownTest() {
 
 func (suite *CustomerServiceTestSuite) TestCreateCustomer_Success() {
 	// Arrange
-	customerData := &domain.Customer{
-		Name:        "John Doe",
-		Code:        "CUST001",
-		PhoneNumber: "+254700123456",
-		Email:       "john@example.com",
+	customerData := &models.Customer{
+		Name:  "John Doe",
+		Code:  "CUST001",
+		Phone: "+254700123456",
+		Email: "john@example.com",
 	}
 
-	expectedCustomer := &domain.Customer{
-		ID:          uuid.New(),
-		Name:        "John Doe",
-		Code:        "CUST001",
-		PhoneNumber: "+254700123456",
-		Email:       "john@example.com",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	expectedCustomer := &models.Customer{
+		ID:        uuid.New(),
+		Name:      "John Doe",
+		Code:      "CUST001",
+		Phone:     "+254700123456",
+		Email:     "john@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 
 	suite.mockRepo.On("Create", suite.ctx, customerData).Return(expectedCustomer, nil)
@@ -61,12 +63,12 @@ func (suite *CustomerServiceTestSuite) TestCreateCustomer_Success() {
 	assert.Equal(suite.T(), expectedCustomer.Name, result.Name)
 	assert.Equal(suite.T(), expectedCustomer.Code, result.Code)
 	assert.Equal(suite.T(), expectedCustomer.Email, result.Email)
-	assert.Equal(suite.T(), expectedCustomer.PhoneNumber, result.PhoneNumber)
+	assert.Equal(suite.T(), expectedCustomer.Phone, result.Phone)
 }
 
 func (suite *CustomerServiceTestSuite) TestCreateCustomer_ValidationError() {
 	// Arrange
-	invalidCustomer := &domain.Customer{
+	invalidCustomer := &models.Customer{
 		Name: "", // Invalid: empty name
 		Code: "CUST001",
 	}
@@ -83,12 +85,12 @@ func (suite *CustomerServiceTestSuite) TestCreateCustomer_ValidationError() {
 func (suite *CustomerServiceTestSuite) TestGetCustomerByID_Success() {
 	// Arrange
 	customerID := uuid.New()
-	expectedCustomer := &domain.Customer{
-		ID:          customerID,
-		Name:        "Jane Doe",
-		Code:        "CUST002",
-		PhoneNumber: "+254700654321",
-		Email:       "jane@example.com",
+	expectedCustomer := &models.Customer{
+		ID:    customerID,
+		Name:  "Jane Doe",
+		Code:  "CUST002",
+		Phone: "+254700654321",
+		Email: "jane@example.com",
 	}
 
 	suite.mockRepo.On("GetByID", suite.ctx, customerID).Return(expectedCustomer, nil)
@@ -106,7 +108,7 @@ func (suite *CustomerServiceTestSuite) TestGetCustomerByID_Success() {
 func (suite *CustomerServiceTestSuite) TestGetCustomerByID_NotFound() {
 	// Arrange
 	customerID := uuid.New()
-	suite.mockRepo.On("GetByID", suite.ctx, customerID).Return(nil, domain.ErrCustomerNotFound)
+	suite.mockRepo.On("GetByID", suite.ctx, customerID).Return(nil, models.ErrCustomerNotFound)
 
 	// Act
 	result, err := suite.service.GetCustomerByID(suite.ctx, customerID)
@@ -114,12 +116,12 @@ func (suite *CustomerServiceTestSuite) TestGetCustomerByID_NotFound() {
 	// Assert
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), result)
-	assert.Equal(suite.T(), domain.ErrCustomerNotFound, err)
+	assert.Equal(suite.T(), models.ErrCustomerNotFound, err)
 }
 
 func (suite *CustomerServiceTestSuite) TestListCustomers_Success() {
 	// Arrange
-	expectedCustomers := []*domain.Customer{
+	expectedCustomers := []*models.Customer{
 		{
 			ID:   uuid.New(),
 			Name: "Customer 1",
@@ -146,18 +148,18 @@ func (suite *CustomerServiceTestSuite) TestListCustomers_Success() {
 func (suite *CustomerServiceTestSuite) TestUpdateCustomer_Success() {
 	// Arrange
 	customerID := uuid.New()
-	updateData := &domain.Customer{
-		Name:        "Updated Name",
-		PhoneNumber: "+254700999888",
+	updateData := &models.Customer{
+		Name:  "Updated Name",
+		Phone: "+254700999888",
 	}
 
-	expectedCustomer := &domain.Customer{
-		ID:          customerID,
-		Name:        "Updated Name",
-		Code:        "CUST001",
-		PhoneNumber: "+254700999888",
-		Email:       "john@example.com",
-		UpdatedAt:   time.Now(),
+	expectedCustomer := &models.Customer{
+		ID:        customerID,
+		Name:      "Updated Name",
+		Code:      "CUST001",
+		Phone:     "+254700999888",
+		Email:     "john@example.com",
+		UpdatedAt: time.Now(),
 	}
 
 	suite.mockRepo.On("Update", suite.ctx, customerID, updateData).Return(expectedCustomer, nil)
@@ -169,7 +171,96 @@ func (suite *CustomerServiceTestSuite) TestUpdateCustomer_Success() {
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), result)
 	assert.Equal(suite.T(), expectedCustomer.Name, result.Name)
-	assert.Equal(suite.T(), expectedCustomer.PhoneNumber, result.PhoneNumber)
+	assert.Equal(suite.T(), expectedCustomer.Phone, result.Phone)
+}
+
+func (suite *CustomerServiceTestSuite) TestCreateCustomer_GeneratesCodeWhenOmitted() {
+	suite.service.SetCodeGeneration(true, "CUST")
+
+	var seenCodes []string
+	duplicate := &pgconn.PgError{Code: "23505"}
+
+	// First generated code collides; the retry succeeds
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Customer")).
+		Run(func(args mock.Arguments) {
+			seenCodes = append(seenCodes, args.Get(1).(*models.Customer).Code)
+		}).
+		Return(nil, duplicate).Once()
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Customer")).
+		Run(func(args mock.Arguments) {
+			customer := args.Get(1).(*models.Customer)
+			seenCodes = append(seenCodes, customer.Code)
+		}).
+		Return(&models.Customer{ID: uuid.New(), Name: "John Doe"}, nil).Once()
+
+	result, err := suite.service.CreateCustomer(suite.ctx, &models.Customer{
+		Name:  "John Doe",
+		Phone: "+254700123456",
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+	assert.Len(suite.T(), seenCodes, 2)
+	assert.NotEqual(suite.T(), seenCodes[0], seenCodes[1], "collision must trigger a fresh code")
+	for _, code := range seenCodes {
+		assert.Regexp(suite.T(), "^CUST[A-Z2-9]{8}$", code)
+	}
+}
+
+func (suite *CustomerServiceTestSuite) TestCreateCustomer_SuppliedCodeStillWorks() {
+	suite.service.SetCodeGeneration(true, "CUST")
+
+	supplied := &models.Customer{Name: "John Doe", Code: "MYCODE01", Phone: "+254700123456"}
+	suite.mockRepo.On("Create", suite.ctx, supplied).Return(supplied, nil)
+
+	result, err := suite.service.CreateCustomer(suite.ctx, supplied)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "MYCODE01", result.Code)
+}
+
+func (suite *CustomerServiceTestSuite) TestCreateCustomer_OmittedCodeWithoutGeneration() {
+	result, err := suite.service.CreateCustomer(suite.ctx, &models.Customer{
+		Name:  "John Doe",
+		Phone: "+254700123456",
+	})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Contains(suite.T(), err.Error(), "code is required")
+}
+
+func (suite *CustomerServiceTestSuite) TestExportCustomers_ResumesAcrossChunks() {
+	makeCustomers := func(n int) []models.Customer {
+		customers := make([]models.Customer, n)
+		for i := range customers {
+			customers[i] = models.Customer{ID: uuid.New(), Code: "CUST001", Name: "Customer"}
+		}
+		return customers
+	}
+
+	firstChunk := makeCustomers(2)
+	lastChunk := makeCustomers(1)
+
+	suite.mockRepo.On("ListAfter", suite.ctx, uuid.Nil, 2).Return(firstChunk, nil)
+	suite.mockRepo.On("ListAfter", suite.ctx, firstChunk[1].ID, 2).Return(lastChunk, nil)
+
+	// First chunk fills the limit, so a cursor is handed back
+	customers, cursor, err := suite.service.ExportCustomers(suite.ctx, "", 2)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), customers, 2)
+	assert.NotEmpty(suite.T(), cursor)
+
+	// Resuming with the cursor returns the remainder and ends the export
+	customers, cursor, err = suite.service.ExportCustomers(suite.ctx, cursor, 2)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), customers, 1)
+	assert.Empty(suite.T(), cursor)
+}
+
+func (suite *CustomerServiceTestSuite) TestExportCustomers_RejectsBadCursor() {
+	_, _, err := suite.service.ExportCustomers(suite.ctx, "not!!valid", 10)
+	assert.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, models.ErrValidation)
 }
 
 func (suite *CustomerServiceTestSuite) TestDeleteCustomer_Success() {
@@ -184,6 +275,100 @@ func (suite *CustomerServiceTestSuite) TestDeleteCustomer_Success() {
 	assert.NoError(suite.T(), err)
 }
 
+func (suite *CustomerServiceTestSuite) TestCreateCustomer_PhoneUniquenessFlag() {
+	suite.T().Setenv("FEATURE_PHONE_UNIQUENESS", "true")
+	suite.service.SetFeatureFlags(config.LoadFeatureFlags())
+
+	customerData := &models.Customer{
+		Name:  "Jane Doe",
+		Code:  "CUST010",
+		Phone: "+254700123456",
+	}
+	suite.mockRepo.On("ExistsByPhone", suite.ctx, "+254700123456").Return(true, nil)
+
+	_, err := suite.service.CreateCustomer(suite.ctx, customerData)
+	suite.Require().Error(err)
+	suite.ErrorIs(err, models.ErrValidation)
+	suite.Contains(err.Error(), "phone number is already in use")
+	// Create must never be reached (no .On for it; AssertExpectations
+	// in teardown catches unexpected calls)
+}
+
+func (suite *CustomerServiceTestSuite) TestCreateCustomer_PhoneUniquenessOffByDefault() {
+	suite.service.SetFeatureFlags(config.LoadFeatureFlags())
+
+	customerData := &models.Customer{
+		Name:  "Jane Doe",
+		Code:  "CUST011",
+		Phone: "+254700123456",
+	}
+	// With the flag off the repo is never probed for the phone
+	suite.mockRepo.On("Create", suite.ctx, customerData).Return(customerData, nil)
+
+	_, err := suite.service.CreateCustomer(suite.ctx, customerData)
+	suite.NoError(err)
+	suite.mockRepo.AssertNotCalled(suite.T(), "ExistsByPhone", mock.Anything, mock.Anything)
+}
+
 func TestCustomerServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(CustomerServiceTestSuite))
-}
\ No newline at end of file
+}
+
+func (suite *CustomerServiceTestSuite) TestCreateCustomer_NormalizesCodeCase() {
+	var storedCode string
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Customer")).
+		Run(func(args mock.Arguments) {
+			storedCode = args.Get(1).(*models.Customer).Code
+		}).
+		Return(&models.Customer{ID: uuid.New()}, nil)
+
+	_, err := suite.service.CreateCustomer(suite.ctx, &models.Customer{
+		Name:  "John Doe",
+		Code:  "cust001",
+		Phone: "+254700123456",
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "CUST001", storedCode)
+}
+
+func (suite *CustomerServiceTestSuite) TestExportUsesAlternateStoreWhenConfigured() {
+	exportRepo := new(mocks.CustomerRepository)
+	suite.service.SetExportStore(exportRepo)
+
+	// The export read hits the alternate (replica-backed) store, not the
+	// primary repository
+	exportRepo.On("ListAfter", suite.ctx, uuid.Nil, 10).Return([]models.Customer{}, nil)
+
+	_, cursor, err := suite.service.ExportCustomers(suite.ctx, "", 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), cursor)
+	exportRepo.AssertExpectations(suite.T())
+}
+
+func (suite *CustomerServiceTestSuite) TestSaveCustomer_RejectsClearingAllChannels() {
+	// A PATCH that cleared both phone and email must not persist: the
+	// customer would be unreachable for order notifications
+	result, err := suite.service.SaveCustomer(suite.ctx, &models.Customer{
+		ID:   uuid.New(),
+		Name: "John Doe",
+		Code: "CUST001",
+	})
+
+	assert.Nil(suite.T(), result)
+	assert.ErrorIs(suite.T(), err, models.ErrValidation)
+}
+
+func (suite *CustomerServiceTestSuite) TestSaveCustomer_ValidPatchPersists() {
+	customer := &models.Customer{
+		ID:    uuid.New(),
+		Name:  "John Doe",
+		Code:  "CUST001",
+		Email: "john@example.com",
+	}
+	suite.mockRepo.On("Save", suite.ctx, customer).Return(customer, nil)
+
+	result, err := suite.service.SaveCustomer(suite.ctx, customer)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), result)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"backend/pkg/models"
+)
+
+// Notifier delivers an order notification over one channel (SMS, email, ...)
+type Notifier interface {
+	// Name identifies the channel for logging
+	Name() string
+	// CanNotify reports whether the customer has what this channel needs
+	CanNotify(customer *models.Customer) bool
+	// Notify queues or sends the order notification
+	Notify(ctx context.Context, order *models.Order) error
+}
+
+// NotificationDispatcher routes an order notification to the first channel
+// able to reach the customer, so customers without a phone number can
+// still be notified by email
+type NotificationDispatcher struct {
+	notifiers []Notifier
+}
+
+// NewNotificationDispatcher creates a dispatcher trying the channels in order
+func NewNotificationDispatcher(notifiers ...Notifier) *NotificationDispatcher {
+	return &NotificationDispatcher{notifiers: notifiers}
+}
+
+// Dispatch notifies the customer over the first available channel. The
+// order's Customer relation must be hydrated; channel selection reads the
+// customer's contact details directly.
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, order *models.Order) error {
+	for _, notifier := range d.notifiers {
+		if !notifier.CanNotify(&order.Customer) {
+			continue
+		}
+		if err := notifier.Notify(ctx, order); err != nil {
+			return fmt.Errorf("%s notification failed: %w", notifier.Name(), err)
+		}
+		return nil
+	}
+	return fmt.Errorf("customer %s has no reachable notification channel", order.CustomerID)
+}
+
+// Name identifies the SMS channel
+func (s *SMSService) Name() string {
+	return "sms"
+}
+
+// CanNotify reports whether the customer can receive SMS
+func (s *SMSService) CanNotify(customer *models.Customer) bool {
+	return customer.Phone != ""
+}
+
+// Notify queues the order SMS, satisfying the Notifier interface
+func (s *SMSService) Notify(ctx context.Context, order *models.Order) error {
+	return s.QueueSMS(ctx, order)
+}
+
+// EmailNotifier is a stub email channel: it renders the notification and
+// records/logs it instead of talking to a real provider yet
+type EmailNotifier struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+// NewEmailNotifier creates the stub email channel
+func NewEmailNotifier() *EmailNotifier {
+	return &EmailNotifier{}
+}
+
+// Name identifies the email channel
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+// CanNotify reports whether the customer has an email address
+func (e *EmailNotifier) CanNotify(customer *models.Customer) bool {
+	return customer.Email != ""
+}
+
+// Notify records the email that would be sent
+func (e *EmailNotifier) Notify(ctx context.Context, order *models.Order) error {
+	e.mu.Lock()
+	e.sent = append(e.sent, order.Customer.Email)
+	e.mu.Unlock()
+
+	log.Printf("Email notification for order %s would be sent to %s", order.ID, order.Customer.Email)
+	return nil
+}
+
+// SentTo returns the recipients recorded so far (test/diagnostic helper)
+func (e *EmailNotifier) SentTo() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.sent...)
+}
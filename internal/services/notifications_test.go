@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend/pkg/models"
+)
+
+func dispatcherFixture() (*NotificationDispatcher, *captureJobQueue, *EmailNotifier) {
+	queue := &captureJobQueue{}
+	smsService := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+	email := NewEmailNotifier()
+	return NewNotificationDispatcher(smsService, email), queue, email
+}
+
+func TestDispatch_PrefersSMSWhenPhonePresent(t *testing.T) {
+	dispatcher, queue, email := dispatcherFixture()
+
+	order := testOrderWithLocale("en")
+	order.Customer.Email = "john@example.com"
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), order))
+
+	assert.Len(t, queue.enqueued, 1)
+	assert.Empty(t, email.SentTo())
+}
+
+func TestDispatch_FallsBackToEmailWithoutPhone(t *testing.T) {
+	dispatcher, queue, email := dispatcherFixture()
+
+	order := testOrderWithLocale("en")
+	order.Customer.Phone = ""
+	order.Customer.Email = "john@example.com"
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), order))
+
+	assert.Empty(t, queue.enqueued)
+	assert.Equal(t, []string{"john@example.com"}, email.SentTo())
+}
+
+func TestDispatch_ErrorsWithNoChannel(t *testing.T) {
+	dispatcher, queue, email := dispatcherFixture()
+
+	order := testOrderWithLocale("en")
+	order.Customer.Phone = ""
+	order.Customer.Email = ""
+
+	err := dispatcher.Dispatch(context.Background(), order)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no reachable notification channel")
+	assert.Empty(t, queue.enqueued)
+	assert.Empty(t, email.SentTo())
+}
+
+func TestValidateCustomer_RequiresAChannel(t *testing.T) {
+	err := validateCustomer(&models.Customer{ID: uuid.New(), Name: "John", Code: "CUST001"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, models.ErrValidation)
+
+	// Email-only customers are fine now
+	assert.NoError(t, validateCustomer(&models.Customer{Name: "John", Code: "CUST001", Email: "j@example.com"}))
+	assert.NoError(t, validateCustomer(&models.Customer{Name: "John", Code: "CUST001", Phone: "+254700123456"}))
+}
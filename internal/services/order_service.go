@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// OrderStore is the repository interface the order service depends on
+type OrderStore interface {
+	Create(ctx context.Context, order *models.Order) (*models.Order, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error)
+	List(ctx context.Context, query *models.ListOrdersQuery) ([]models.OrderListItem, int64, bool, error)
+	Recent(ctx context.Context, limit int) ([]models.Order, error)
+	GetByCustomerID(ctx context.Context, customerID uuid.UUID) ([]models.Order, error)
+	Update(ctx context.Context, order *models.Order) (*models.Order, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status models.OrderStatus, smsSentAt *time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetPendingSMSOrders(ctx context.Context) ([]models.Order, error)
+	GetUnnotifiedOrdersBetween(ctx context.Context, from, to time.Time) ([]models.Order, error)
+	ExistsByID(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// OrderEventStore persists the append-only order lifecycle log
+type OrderEventStore interface {
+	Append(ctx context.Context, event *models.OrderEvent) error
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]models.OrderEvent, error)
+}
+
+// DuplicateOrderGuard claims a submission fingerprint for a short window
+// so rapid identical submissions are rejected. A claim consumed by a
+// request that then failed must be released, or the client's retry would
+// be bounced as a duplicate of an order that was never created.
+type DuplicateOrderGuard interface {
+	Claim(ctx context.Context, fingerprint string) (bool, error)
+	Release(ctx context.Context, fingerprint string) error
+}
+
+// OrderRateLimiter counts a customer's recent order creations to curb abuse
+type OrderRateLimiter interface {
+	Take(ctx context.Context, customerID uuid.UUID) (bool, error)
+}
+
+// OrderService holds order business rules: customer existence, amount
+// validation, status transitions and SMS notification, decoupled from HTTP
+type OrderService struct {
+	repo       OrderStore
+	customers  CustomerStore
+	smsService *SMSService
+	// maxAutoAmount flags orders above this amount for manual review;
+	// zero disables the check
+	maxAutoAmount models.Money
+	// autoConfirm advances new orders from pending to confirmed
+	autoConfirm bool
+	// notifier routes order notifications over the available channel
+	notifier *NotificationDispatcher
+	// rateLimiter optionally caps orders per customer per window
+	rateLimiter OrderRateLimiter
+	// events records the order lifecycle log when configured
+	events OrderEventStore
+	// dupGuard optionally rejects identical rapid submissions
+	dupGuard DuplicateOrderGuard
+}
+
+// NewOrderService creates a new order service
+func NewOrderService(repo OrderStore, customers CustomerStore, smsService *SMSService) *OrderService {
+	return &OrderService{
+		repo:       repo,
+		customers:  customers,
+		smsService: smsService,
+		notifier:   NewNotificationDispatcher(smsService, NewEmailNotifier()),
+	}
+}
+
+// SetMaxAutoAmount enables review flagging for orders above the threshold
+func (s *OrderService) SetMaxAutoAmount(amount float64) {
+	s.maxAutoAmount = models.Money(amount)
+}
+
+// SetAutoConfirm makes new orders advance straight to confirmed; some
+// deployments require a manual confirmation step instead
+func (s *OrderService) SetAutoConfirm(autoConfirm bool) {
+	s.autoConfirm = autoConfirm
+}
+
+// SetRateLimiter caps how many orders a customer can create per window
+func (s *OrderService) SetRateLimiter(limiter OrderRateLimiter) {
+	s.rateLimiter = limiter
+}
+
+// SetDuplicateGuard rejects identical submissions within a short window
+func (s *OrderService) SetDuplicateGuard(guard DuplicateOrderGuard) {
+	s.dupGuard = guard
+}
+
+// orderFingerprint identifies a logical submission for dedup purposes
+func orderFingerprint(customerID uuid.UUID, item string, amount models.Money) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%.2f", customerID, item, float64(amount))))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetEventStore enables the append-only order lifecycle log
+func (s *OrderService) SetEventStore(events OrderEventStore) {
+	s.events = events
+}
+
+// recordEvent appends a lifecycle event; event-log failures are logged
+// rather than failing the operation they describe
+func (s *OrderService) recordEvent(ctx context.Context, orderID uuid.UUID, eventType, detail string) {
+	if s.events == nil {
+		return
+	}
+	event := &models.OrderEvent{
+		OrderID: orderID,
+		Type:    eventType,
+		Actor:   "api",
+		Detail:  detail,
+	}
+	if err := s.events.Append(ctx, event); err != nil {
+		log.Printf("Failed to record %s event for order %s: %v", eventType, orderID, err)
+	}
+}
+
+// OrderEvents returns the lifecycle log for an order, verifying the order
+// exists first
+func (s *OrderService) OrderEvents(ctx context.Context, orderID uuid.UUID) ([]models.OrderEvent, error) {
+	if _, err := s.repo.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if s.events == nil {
+		return []models.OrderEvent{}, nil
+	}
+	return s.events.ListByOrder(ctx, orderID)
+}
+
+// CreateOrder validates the request, persists the order and queues the
+// confirmation SMS
+func (s *OrderService) CreateOrder(ctx context.Context, req *models.CreateOrderRequest) (*models.Order, error) {
+	// Multi-item orders derive their amount and summary from the line
+	// items; the legacy single item+amount shape keeps working
+	items, item, amount, err := resolveOrderContents(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify customer exists
+	customer, err := s.customers.GetByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject identical rapid submissions (double clicks) unless forced.
+	// The claim is remembered so failure paths below can release it: the
+	// fingerprint must only stay consumed by an order that actually exists.
+	claimedFingerprint := ""
+	if s.dupGuard != nil && !req.Force {
+		fingerprint := orderFingerprint(req.CustomerID, item, amount)
+		first, err := s.dupGuard.Claim(ctx, fingerprint)
+		if err != nil {
+			log.Printf("Duplicate-order guard unavailable, allowing order: %v", err)
+		} else if !first {
+			return nil, models.ErrPossibleDuplicate
+		} else {
+			claimedFingerprint = fingerprint
+		}
+	}
+	releaseClaim := func() {
+		if claimedFingerprint == "" {
+			return
+		}
+		if err := s.dupGuard.Release(ctx, claimedFingerprint); err != nil {
+			log.Printf("Failed to release duplicate-order claim: %v", err)
+		}
+	}
+
+	// Optional abuse guard: cap orders per customer per window
+	if s.rateLimiter != nil {
+		allowed, err := s.rateLimiter.Take(ctx, req.CustomerID)
+		if err != nil {
+			log.Printf("Order rate limiter unavailable, allowing order: %v", err)
+		} else if !allowed {
+			releaseClaim()
+			return nil, models.ErrOrderRateLimited
+		}
+	}
+
+	// Set ordered_at if not provided
+	orderedAt := time.Now()
+	if req.OrderedAt != nil {
+		orderedAt = *req.OrderedAt
+	}
+
+	// Orders above the auto-processing threshold are parked for manual
+	// review and don't trigger the confirmation SMS
+	status := models.OrderStatusPending
+	needsReview := s.maxAutoAmount > 0 && amount > s.maxAutoAmount
+	if needsReview {
+		status = models.OrderStatusNeedsReview
+	}
+
+	order := &models.Order{
+		CustomerID: req.CustomerID,
+		Item:       item,
+		Amount:     amount,
+		Items:      items,
+		OrderedAt:  orderedAt,
+		Status:     status,
+		Version:    1,
+		IsActive:   true,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	order, err = s.repo.Create(ctx, order)
+	if err != nil {
+		releaseClaim()
+		return nil, err
+	}
+
+	// Load customer relationship for the response and the SMS template
+	order.Customer = *customer
+
+	s.recordEvent(ctx, order.ID, models.OrderEventCreated, "")
+
+	// Optionally auto-advance fresh orders through the state machine
+	if s.autoConfirm && order.Status == models.OrderStatusPending {
+		if err := models.ValidateOrderStatusTransition(order.Status, models.OrderStatusConfirmed); err == nil {
+			if err := s.repo.UpdateStatus(ctx, order.ID, models.OrderStatusConfirmed, nil); err != nil {
+				log.Printf("Failed to auto-confirm order %s: %v", order.ID, err)
+			} else {
+				order.Status = models.OrderStatusConfirmed
+				s.recordEvent(ctx, order.ID, models.OrderEventConfirmed, "auto-confirm")
+			}
+		}
+	}
+
+	// Notify the customer over the best available channel (SMS, falling
+	// back to email); a notification failure must not fail the order itself
+	if !needsReview {
+		if err := s.notifier.Dispatch(ctx, order); err != nil {
+			log.Printf("Failed to notify customer for order %s: %v", order.ID, err)
+		}
+	}
+
+	return order, nil
+}
+
+// PreviewOrder runs every order-creation validation and returns the order
+// and SMS message that would result, without persisting or enqueuing
+// anything
+func (s *OrderService) PreviewOrder(ctx context.Context, req *models.CreateOrderRequest) (*models.Order, SMSPreview, error) {
+	items, item, amount, err := resolveOrderContents(req)
+	if err != nil {
+		return nil, SMSPreview{}, err
+	}
+
+	customer, err := s.customers.GetByID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, SMSPreview{}, err
+	}
+
+	orderedAt := time.Now()
+	if req.OrderedAt != nil {
+		orderedAt = *req.OrderedAt
+	}
+
+	status := models.OrderStatusPending
+	if s.maxAutoAmount > 0 && amount > s.maxAutoAmount {
+		status = models.OrderStatusNeedsReview
+	}
+
+	order := &models.Order{
+		CustomerID: req.CustomerID,
+		Item:       item,
+		Amount:     amount,
+		Items:      items,
+		OrderedAt:  orderedAt,
+		Status:     status,
+		Customer:   *customer,
+	}
+
+	return order, s.smsService.PreviewOrderSMS(ctx, order), nil
+}
+
+// resolveOrderContents validates the request's item shape and returns the
+// line items, the legacy item summary and the order amount
+func resolveOrderContents(req *models.CreateOrderRequest) ([]models.OrderItem, string, models.Money, error) {
+	if len(req.Items) == 0 {
+		// Legacy single-item shape
+		if req.Item == "" {
+			return nil, "", 0, fmt.Errorf("%w: item or items is required", models.ErrValidation)
+		}
+		if req.Amount <= 0 {
+			return nil, "", 0, fmt.Errorf("%w: amount must be greater than zero", models.ErrValidation)
+		}
+		return nil, req.Item, models.Money(req.Amount), nil
+	}
+
+	items := make([]models.OrderItem, 0, len(req.Items))
+	for _, itemReq := range req.Items {
+		if itemReq.Quantity <= 0 {
+			return nil, "", 0, fmt.Errorf("%w: item quantity must be greater than zero", models.ErrValidation)
+		}
+		if itemReq.UnitPrice <= 0 {
+			return nil, "", 0, fmt.Errorf("%w: item unit price must be greater than zero", models.ErrValidation)
+		}
+		items = append(items, models.OrderItem{
+			Name:      itemReq.Name,
+			Quantity:  itemReq.Quantity,
+			UnitPrice: models.Money(itemReq.UnitPrice),
+		})
+	}
+
+	// Keep the legacy item column meaningful for multi-item orders
+	summary := items[0].Name
+	if len(items) > 1 {
+		summary = fmt.Sprintf("%s (+%d more)", items[0].Name, len(items)-1)
+	}
+
+	return items, summary, models.OrderItemsTotal(items), nil
+}
+
+// GetOrder returns a single active order with its customer preloaded
+func (s *OrderService) GetOrder(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListOrders returns a filtered page of order list items, with either the
+// total match count or a has-more flag depending on query.Count
+func (s *OrderService) ListOrders(ctx context.Context, query *models.ListOrdersQuery) ([]models.OrderListItem, int64, bool, error) {
+	return s.repo.List(ctx, query)
+}
+
+// RecentOrders returns the N most recent orders across all customers,
+// defaulting and capping the limit to the shared page bounds
+func (s *OrderService) RecentOrders(ctx context.Context, limit int) ([]models.Order, error) {
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	return s.repo.Recent(ctx, limit)
+}
+
+// GetCustomerOrders returns all orders for a customer, verifying the
+// customer exists first
+func (s *OrderService) GetCustomerOrders(ctx context.Context, customerID uuid.UUID) ([]models.Order, error) {
+	if _, err := s.customers.GetByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByCustomerID(ctx, customerID)
+}
+
+// CorrectOrder updates a pending order's item or amount before
+// fulfilment. The audit trigger records the prior version in history, and
+// amount-based rules (the needs-review threshold) are re-evaluated.
+func (s *OrderService) CorrectOrder(ctx context.Context, id uuid.UUID, req *models.PatchOrderRequest) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != models.OrderStatusPending {
+		return nil, fmt.Errorf("%w: only pending orders can be corrected (current status %q)", models.ErrValidation, order.Status)
+	}
+
+	if req.Item != nil {
+		order.Item = *req.Item
+	}
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			return nil, fmt.Errorf("%w: amount must be greater than zero", models.ErrValidation)
+		}
+		order.Amount = models.Money(*req.Amount)
+	}
+
+	// Re-evaluate amount-based rules after the correction
+	if s.maxAutoAmount > 0 && order.Amount > s.maxAutoAmount {
+		order.Status = models.OrderStatusNeedsReview
+	}
+	order.UpdatedAt = time.Now()
+
+	updated, err := s.repo.Update(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, order.ID, models.OrderEventCorrected, "")
+	return updated, nil
+}
+
+// BackfillSMS re-enqueues confirmation SMS for orders in the range whose
+// notification was never sent (e.g. after a bug dropped them), returning
+// how many were queued. The dedup keys keep this safe to re-run.
+func (s *OrderService) BackfillSMS(ctx context.Context, from, to time.Time) (int, error) {
+	orders, err := s.repo.GetUnnotifiedOrdersBetween(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for i := range orders {
+		order := &orders[i]
+		if err := s.smsService.QueueSMS(ctx, order); err != nil {
+			// The original job may still be pending; that's not a failure
+			if errors.Is(err, ErrDuplicateJob) {
+				continue
+			}
+			log.Printf("SMS backfill failed to enqueue order %s: %v", order.ID, err)
+			continue
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+// OrderExists reports whether an active order exists, without fetching
+// the row
+func (s *OrderService) OrderExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.repo.ExistsByID(ctx, id)
+}
+
+// CancelOrder transitions an order to cancelled, enforcing the status
+// transition rules
+func (s *OrderService) CancelOrder(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateOrderStatusTransition(order.Status, models.OrderStatusCancelled); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrValidation, err)
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, models.OrderStatusCancelled, nil); err != nil {
+		return nil, err
+	}
+
+	order.Status = models.OrderStatusCancelled
+	s.recordEvent(ctx, order.ID, models.OrderEventCancelled, "")
+	return order, nil
+}
@@ -0,0 +1,606 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"backend/internal/repositories/mocks"
+	"backend/pkg/models"
+)
+
+type OrderServiceTestSuite struct {
+	suite.Suite
+	service      *OrderService
+	mockRepo     *mocks.OrderRepository
+	mockCustomer *mocks.CustomerRepository
+	jobQueue     *captureJobQueue
+	ctx          context.Context
+}
+
+func (suite *OrderServiceTestSuite) SetupTest() {
+	suite.mockRepo = new(mocks.OrderRepository)
+	suite.mockCustomer = new(mocks.CustomerRepository)
+	suite.jobQueue = &captureJobQueue{}
+
+	smsService := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, suite.jobQueue)
+	suite.service = NewOrderService(suite.mockRepo, suite.mockCustomer, smsService)
+	suite.ctx = context.Background()
+}
+
+func (suite *OrderServiceTestSuite) TearDownTest() {
+	suite.mockRepo.AssertExpectations(suite.T())
+	suite.mockCustomer.AssertExpectations(suite.T())
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_Success() {
+	customerID := uuid.New()
+	customer := &models.Customer{
+		ID:    customerID,
+		Name:  "John Doe",
+		Code:  "CUST001",
+		Phone: "+254700123456",
+	}
+
+	req := &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     999.99,
+	}
+
+	created := &models.Order{
+		ID:         uuid.New(),
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     999.99,
+		Status:     models.OrderStatusPending,
+	}
+
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Order")).Return(created, nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, req)
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), order)
+	assert.Equal(suite.T(), models.OrderStatusPending, order.Status)
+	assert.Equal(suite.T(), customer.Name, order.Customer.Name)
+
+	// The confirmation SMS is queued with the customer's phone
+	assert.Len(suite.T(), suite.jobQueue.enqueued, 1)
+	assert.Equal(suite.T(), customer.Phone, suite.jobQueue.enqueued[0].Phone)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_InvalidAmount() {
+	req := &models.CreateOrderRequest{
+		CustomerID: uuid.New(),
+		Item:       "Laptop",
+		Amount:     0,
+	}
+
+	order, err := suite.service.CreateOrder(suite.ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), order)
+	assert.ErrorIs(suite.T(), err, models.ErrValidation)
+	assert.Empty(suite.T(), suite.jobQueue.enqueued)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_CustomerNotFound() {
+	customerID := uuid.New()
+	req := &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     10,
+	}
+
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(nil, models.ErrCustomerNotFound)
+
+	order, err := suite.service.CreateOrder(suite.ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), order)
+	assert.ErrorIs(suite.T(), err, models.ErrCustomerNotFound)
+}
+
+func (suite *OrderServiceTestSuite) TestGetOrder_Success() {
+	orderID := uuid.New()
+	expected := &models.Order{ID: orderID, Item: "Laptop", Status: models.OrderStatusPending}
+
+	suite.mockRepo.On("GetByID", suite.ctx, orderID).Return(expected, nil)
+
+	order, err := suite.service.GetOrder(suite.ctx, orderID)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), expected, order)
+}
+
+func (suite *OrderServiceTestSuite) TestGetOrder_NotFound() {
+	orderID := uuid.New()
+	suite.mockRepo.On("GetByID", suite.ctx, orderID).Return(nil, models.ErrOrderNotFound)
+
+	order, err := suite.service.GetOrder(suite.ctx, orderID)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), order)
+	assert.ErrorIs(suite.T(), err, models.ErrOrderNotFound)
+}
+
+func (suite *OrderServiceTestSuite) TestListOrders_Success() {
+	query := &models.ListOrdersQuery{PageParams: models.PageParams{Limit: 10}}
+	expected := []models.OrderListItem{
+		{ID: uuid.New(), Item: "Laptop", CustomerName: "John"},
+		{ID: uuid.New(), Item: "Phone", CustomerName: "Jane"},
+	}
+
+	suite.mockRepo.On("List", suite.ctx, query).Return(expected, int64(2), false, nil)
+
+	orders, total, _, err := suite.service.ListOrders(suite.ctx, query)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), total)
+	assert.Len(suite.T(), orders, 2)
+}
+
+func (suite *OrderServiceTestSuite) TestPreviewOrder_NoPersistenceOrEnqueue() {
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456", Locale: "en"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	// No Create expectation: a repo write would fail AssertExpectations
+	order, preview, err := suite.service.PreviewOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     999.99,
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uuid.Nil, order.ID, "previewed orders are never assigned an ID")
+	assert.Equal(suite.T(), models.OrderStatusPending, order.Status)
+	assert.Contains(suite.T(), preview.Message, "John Doe")
+	assert.NotZero(suite.T(), preview.Segments)
+
+	// Nothing was enqueued either
+	assert.Empty(suite.T(), suite.jobQueue.enqueued)
+}
+
+func (suite *OrderServiceTestSuite) TestPreviewOrder_ValidationStillApplies() {
+	_, _, err := suite.service.PreviewOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: uuid.New(),
+		Item:       "Laptop",
+		Amount:     0,
+	})
+	assert.ErrorIs(suite.T(), err, models.ErrValidation)
+}
+
+// captureEventStore records appended lifecycle events in memory
+type captureEventStore struct {
+	events []models.OrderEvent
+}
+
+func (s *captureEventStore) Append(ctx context.Context, event *models.OrderEvent) error {
+	s.events = append(s.events, *event)
+	return nil
+}
+
+func (s *captureEventStore) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]models.OrderEvent, error) {
+	var matched []models.OrderEvent
+	for _, event := range s.events {
+		if event.OrderID == orderID {
+			matched = append(matched, event)
+		}
+	}
+	return matched, nil
+}
+
+func (suite *OrderServiceTestSuite) TestOrderLifecycleEventsRecorded() {
+	events := &captureEventStore{}
+	suite.service.SetEventStore(events)
+	suite.service.SetAutoConfirm(true)
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	created := &models.Order{ID: uuid.New(), CustomerID: customerID, Item: "Laptop", Amount: 100, Status: models.OrderStatusPending}
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Order")).Return(created, nil)
+	suite.mockRepo.On("UpdateStatus", suite.ctx, created.ID, models.OrderStatusConfirmed, (*time.Time)(nil)).Return(nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+	})
+	require.NoError(suite.T(), err)
+
+	// Cancel the confirmed order to complete the flow
+	suite.mockRepo.On("GetByID", suite.ctx, order.ID).Return(order, nil)
+	suite.mockRepo.On("UpdateStatus", suite.ctx, order.ID, models.OrderStatusCancelled, (*time.Time)(nil)).Return(nil)
+	_, err = suite.service.CancelOrder(suite.ctx, order.ID)
+	require.NoError(suite.T(), err)
+
+	var types []string
+	for _, event := range events.events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(suite.T(), []string{
+		models.OrderEventCreated,
+		models.OrderEventConfirmed,
+		models.OrderEventCancelled,
+	}, types)
+}
+
+// stubRateLimiter allows a fixed number of takes
+type stubRateLimiter struct{ remaining int }
+
+func (s *stubRateLimiter) Take(ctx context.Context, customerID uuid.UUID) (bool, error) {
+	if s.remaining <= 0 {
+		return false, nil
+	}
+	s.remaining--
+	return true, nil
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_RateLimited() {
+	suite.service.SetRateLimiter(&stubRateLimiter{remaining: 0})
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+	})
+
+	assert.Nil(suite.T(), order)
+	assert.ErrorIs(suite.T(), err, models.ErrOrderRateLimited)
+	assert.Empty(suite.T(), suite.jobQueue.enqueued)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_AutoConfirmEnabled() {
+	suite.service.SetAutoConfirm(true)
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456", Locale: "en"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	created := &models.Order{ID: uuid.New(), CustomerID: customerID, Item: "Laptop", Amount: 100, Status: models.OrderStatusPending}
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Order")).Return(created, nil)
+	suite.mockRepo.On("UpdateStatus", suite.ctx, created.ID, models.OrderStatusConfirmed, (*time.Time)(nil)).Return(nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.OrderStatusConfirmed, order.Status)
+
+	// The confirmation wording is used, not the plain received wording
+	require.Len(suite.T(), suite.jobQueue.enqueued, 1)
+	assert.Contains(suite.T(), suite.jobQueue.enqueued[0].Message, "confirmed")
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_ManualConfirmationByDefault() {
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456", Locale: "en"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	created := &models.Order{ID: uuid.New(), CustomerID: customerID, Item: "Laptop", Amount: 100, Status: models.OrderStatusPending}
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Order")).Return(created, nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.OrderStatusPending, order.Status)
+	require.Len(suite.T(), suite.jobQueue.enqueued, 1)
+	assert.Contains(suite.T(), suite.jobQueue.enqueued[0].Message, "received")
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_MultiItemDerivesAmount() {
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	var captured *models.Order
+	created := &models.Order{ID: uuid.New(), Status: models.OrderStatusPending}
+	suite.mockRepo.On("Create", suite.ctx, mock.MatchedBy(func(order *models.Order) bool {
+		captured = order
+		return true
+	})).Return(created, nil)
+
+	_, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Items: []models.OrderItemRequest{
+			{Name: "Laptop", Quantity: 2, UnitPrice: 500},
+			{Name: "Mouse", Quantity: 3, UnitPrice: 10.50},
+		},
+	})
+
+	assert.NoError(suite.T(), err)
+	// Amount is the sum of line totals: 2*500 + 3*10.50
+	assert.Equal(suite.T(), models.Money(1031.50), captured.Amount)
+	assert.Len(suite.T(), captured.Items, 2)
+	assert.Equal(suite.T(), "Laptop (+1 more)", captured.Item)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_RejectsInvalidLineItems() {
+	_, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: uuid.New(),
+		Items: []models.OrderItemRequest{
+			{Name: "Laptop", Quantity: 0, UnitPrice: 500},
+		},
+	})
+
+	assert.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, models.ErrValidation)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_AboveThresholdNeedsReview() {
+	suite.service.SetMaxAutoAmount(500)
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	created := &models.Order{ID: uuid.New(), Status: models.OrderStatusNeedsReview, Amount: 1000}
+	suite.mockRepo.On("Create", suite.ctx, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Status == models.OrderStatusNeedsReview
+	})).Return(created, nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Gold Bars",
+		Amount:     1000,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.OrderStatusNeedsReview, order.Status)
+	// Flagged orders don't notify the customer until reviewed
+	assert.Empty(suite.T(), suite.jobQueue.enqueued)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_BelowThresholdAutoProcesses() {
+	suite.service.SetMaxAutoAmount(500)
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	created := &models.Order{ID: uuid.New(), CustomerID: customerID, Status: models.OrderStatusPending, Amount: 100}
+	suite.mockRepo.On("Create", suite.ctx, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Status == models.OrderStatusPending
+	})).Return(created, nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.OrderStatusPending, order.Status)
+	assert.Len(suite.T(), suite.jobQueue.enqueued, 1)
+}
+
+func (suite *OrderServiceTestSuite) TestRecentOrders_DefaultsAndCapsLimit() {
+	recent := []models.Order{{ID: uuid.New(), Item: "Laptop"}}
+
+	// Zero limit falls back to the default page size
+	suite.mockRepo.On("Recent", suite.ctx, models.DefaultPageLimit).Return(recent, nil).Once()
+	orders, err := suite.service.RecentOrders(suite.ctx, 0)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), orders, 1)
+
+	// Oversized limits are capped
+	suite.mockRepo.On("Recent", suite.ctx, models.MaxPageLimit).Return(recent, nil).Once()
+	_, err = suite.service.RecentOrders(suite.ctx, 5000)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *OrderServiceTestSuite) TestCorrectOrder_WhilePending() {
+	suite.service.SetMaxAutoAmount(500)
+
+	orderID := uuid.New()
+	order := &models.Order{ID: orderID, Item: "Lapto", Amount: 100, Status: models.OrderStatusPending}
+
+	suite.mockRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
+	suite.mockRepo.On("Update", suite.ctx, mock.AnythingOfType("*models.Order")).
+		Return(order, nil)
+
+	item := "Laptop"
+	amount := 1000.0
+	corrected, err := suite.service.CorrectOrder(suite.ctx, orderID, &models.PatchOrderRequest{
+		Item:   &item,
+		Amount: &amount,
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Laptop", corrected.Item)
+	assert.Equal(suite.T(), models.Money(1000), corrected.Amount)
+	// The corrected amount crossed the review threshold
+	assert.Equal(suite.T(), models.OrderStatusNeedsReview, corrected.Status)
+}
+
+func (suite *OrderServiceTestSuite) TestCorrectOrder_RejectedWhenNotPending() {
+	orderID := uuid.New()
+	order := &models.Order{ID: orderID, Item: "Laptop", Amount: 100, Status: models.OrderStatusConfirmed}
+
+	suite.mockRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
+
+	amount := 200.0
+	corrected, err := suite.service.CorrectOrder(suite.ctx, orderID, &models.PatchOrderRequest{Amount: &amount})
+
+	assert.Nil(suite.T(), corrected)
+	assert.ErrorIs(suite.T(), err, models.ErrValidation)
+}
+
+func (suite *OrderServiceTestSuite) TestCancelOrder_Success() {
+	orderID := uuid.New()
+	order := &models.Order{ID: orderID, Status: models.OrderStatusPending}
+
+	suite.mockRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
+	suite.mockRepo.On("UpdateStatus", suite.ctx, orderID, models.OrderStatusCancelled, (*time.Time)(nil)).Return(nil)
+
+	cancelled, err := suite.service.CancelOrder(suite.ctx, orderID)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.OrderStatusCancelled, cancelled.Status)
+}
+
+func (suite *OrderServiceTestSuite) TestCancelOrder_InvalidTransition() {
+	orderID := uuid.New()
+	order := &models.Order{ID: orderID, Status: models.OrderStatusDelivered}
+
+	suite.mockRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
+
+	cancelled, err := suite.service.CancelOrder(suite.ctx, orderID)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), cancelled)
+	assert.ErrorIs(suite.T(), err, models.ErrValidation)
+}
+
+func TestOrderServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderServiceTestSuite))
+}
+
+// stubDupGuard rejects every claim once one is held, until released
+type stubDupGuard struct {
+	rejected bool
+	released []string
+}
+
+func (g *stubDupGuard) Claim(ctx context.Context, fingerprint string) (bool, error) {
+	if g.rejected {
+		return false, nil
+	}
+	g.rejected = true
+	return true, nil
+}
+
+func (g *stubDupGuard) Release(ctx context.Context, fingerprint string) error {
+	g.released = append(g.released, fingerprint)
+	g.rejected = false
+	return nil
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_DuplicateSubmissionRejected() {
+	suite.service.SetDuplicateGuard(&stubDupGuard{rejected: true})
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+	})
+
+	assert.Nil(suite.T(), order)
+	assert.ErrorIs(suite.T(), err, models.ErrPossibleDuplicate)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_FailedCreateReleasesDupClaim() {
+	guard := &stubDupGuard{}
+	suite.service.SetDuplicateGuard(guard)
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	request := &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+	}
+
+	// The insert fails after the fingerprint was claimed
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Order")).
+		Return(nil, errors.New("connection reset")).Once()
+
+	_, err := suite.service.CreateOrder(suite.ctx, request)
+	suite.Require().Error(err)
+	suite.Len(guard.released, 1, "failed create must release the dedup claim")
+
+	// The client's retry goes through instead of bouncing as a duplicate
+	created := &models.Order{ID: uuid.New(), CustomerID: customerID, Item: "Laptop", Amount: 100, Status: models.OrderStatusPending}
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Order")).
+		Return(created, nil).Once()
+
+	order, err := suite.service.CreateOrder(suite.ctx, request)
+	suite.Require().NoError(err)
+	suite.NotNil(order)
+}
+
+func (suite *OrderServiceTestSuite) TestCreateOrder_ForcedOverrideSkipsDedup() {
+	suite.service.SetDuplicateGuard(&stubDupGuard{rejected: true})
+
+	customerID := uuid.New()
+	customer := &models.Customer{ID: customerID, Name: "John Doe", Phone: "+254700123456"}
+	suite.mockCustomer.On("GetByID", suite.ctx, customerID).Return(customer, nil)
+
+	created := &models.Order{ID: uuid.New(), CustomerID: customerID, Status: models.OrderStatusPending}
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Order")).Return(created, nil)
+
+	order, err := suite.service.CreateOrder(suite.ctx, &models.CreateOrderRequest{
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     100,
+		Force:      true,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), order)
+}
+
+// dupOnceQueue reports the first enqueue as a duplicate, the rest succeed
+type dupOnceQueue struct {
+	captureJobQueue
+	duplicated bool
+}
+
+func (q *dupOnceQueue) Enqueue(ctx context.Context, job *SMSJob) error {
+	if !q.duplicated {
+		q.duplicated = true
+		return ErrDuplicateJob
+	}
+	return q.captureJobQueue.Enqueue(ctx, job)
+}
+
+func (suite *OrderServiceTestSuite) TestBackfillSMS_QueuesOnlyNewNotifications() {
+	queue := &dupOnceQueue{}
+	smsService := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+	service := NewOrderService(suite.mockRepo, suite.mockCustomer, smsService)
+
+	from := time.Now().Add(-24 * time.Hour)
+	to := time.Now()
+
+	inRange := []models.Order{
+		*testOrderWithLocale("en"),
+		*testOrderWithLocale("en"),
+	}
+	suite.mockRepo.On("GetUnnotifiedOrdersBetween", suite.ctx, from, to).Return(inRange, nil)
+
+	queued, err := service.BackfillSMS(suite.ctx, from, to)
+	require.NoError(suite.T(), err)
+
+	// The first order's SMS was still pending (duplicate) and isn't counted
+	assert.Equal(suite.T(), 1, queued)
+	assert.Len(suite.T(), queue.enqueued, 1)
+}
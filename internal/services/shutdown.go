@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ShutdownHooks are the steps run after the HTTP server has drained:
+// stopping the SMS worker, recording final queue stats and flushing logs.
+// Nil hooks are skipped.
+type ShutdownHooks struct {
+	StopWorker  func()
+	RecordDepth func(ctx context.Context) error
+	FlushLogs   func() error
+}
+
+// GracefulShutdown runs the shutdown hooks in order, bounded by the grace
+// timeout so a hung step can't block process exit indefinitely
+func GracefulShutdown(grace time.Duration, hooks ShutdownHooks) error {
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		if hooks.StopWorker != nil {
+			hooks.StopWorker()
+		}
+		if hooks.RecordDepth != nil {
+			if err := hooks.RecordDepth(ctx); err != nil {
+				done <- fmt.Errorf("failed to record final queue depth: %w", err)
+				return
+			}
+		}
+		if hooks.FlushLogs != nil {
+			if err := hooks.FlushLogs(); err != nil {
+				done <- fmt.Errorf("failed to flush logs: %w", err)
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("shutdown grace period of %v exceeded", grace)
+	case err := <-done:
+		return err
+	}
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGracefulShutdown_RunsHooksInOrder(t *testing.T) {
+	var order []string
+
+	err := GracefulShutdown(time.Second, ShutdownHooks{
+		StopWorker: func() { order = append(order, "stop_worker") },
+		RecordDepth: func(ctx context.Context) error {
+			order = append(order, "record_depth")
+			return nil
+		},
+		FlushLogs: func() error {
+			order = append(order, "flush_logs")
+			return nil
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stop_worker", "record_depth", "flush_logs"}, order)
+}
+
+func TestGracefulShutdown_BoundedByGrace(t *testing.T) {
+	start := time.Now()
+
+	err := GracefulShutdown(50*time.Millisecond, ShutdownHooks{
+		StopWorker: func() { time.Sleep(5 * time.Second) },
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "grace period")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestGracefulShutdown_NilHooksSkipped(t *testing.T) {
+	assert.NoError(t, GracefulShutdown(time.Second, ShutdownHooks{}))
+}
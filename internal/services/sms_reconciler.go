@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// SMSReconciler periodically re-enqueues confirmation SMS for orders whose
+// notification was lost (still pending with sms_sent_at unset). Orders
+// younger than olderThan are skipped so in-flight jobs aren't duplicated.
+type SMSReconciler struct {
+	orders     OrderStore
+	smsService *SMSService
+	interval   time.Duration
+	olderThan  time.Duration
+	lock       ReconcilerLock
+}
+
+// ReconcilerLock gates each reconcile run so only one replica does the
+// work (see internal/lock)
+type ReconcilerLock interface {
+	Acquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// NewSMSReconciler creates a reconciler that runs every interval and only
+// considers orders older than olderThan
+func NewSMSReconciler(orders OrderStore, smsService *SMSService, interval, olderThan time.Duration) *SMSReconciler {
+	return &SMSReconciler{
+		orders:     orders,
+		smsService: smsService,
+		interval:   interval,
+		olderThan:  olderThan,
+	}
+}
+
+// SetLock makes each run exclusive across replicas
+func (r *SMSReconciler) SetLock(lock ReconcilerLock) {
+	r.lock = lock
+}
+
+// Run executes the reconcile loop until the context is cancelled
+func (r *SMSReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// With a lock configured, only one replica runs each sweep
+			if r.lock != nil {
+				held, err := r.lock.Acquire(ctx)
+				if err != nil {
+					log.Printf("SMS reconciler lock unavailable: %v", err)
+					continue
+				}
+				if !held {
+					continue
+				}
+			}
+
+			if count, err := r.ReconcileOnce(ctx); err != nil {
+				log.Printf("SMS reconciler run failed: %v", err)
+			} else if count > 0 {
+				log.Printf("SMS reconciler re-enqueued %d un-notified orders", count)
+			}
+
+			if r.lock != nil {
+				if err := r.lock.Release(ctx); err != nil {
+					log.Printf("SMS reconciler failed to release lock: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// ReconcileOnce finds orders that should have been notified but weren't
+// and re-enqueues their SMS, returning how many were enqueued
+func (r *SMSReconciler) ReconcileOnce(ctx context.Context) (int, error) {
+	orders, err := r.orders.GetPendingSMSOrders(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range orders {
+		order := &orders[i]
+
+		// Give the normal queue path time to deliver before stepping in
+		if time.Since(order.CreatedAt) < r.olderThan {
+			continue
+		}
+
+		if err := r.smsService.QueueSMS(ctx, order); err != nil {
+			// A duplicate just means the original job is still in flight
+			if errors.Is(err, ErrDuplicateJob) {
+				continue
+			}
+			log.Printf("SMS reconciler failed to enqueue order %s: %v", order.ID, err)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/internal/repositories/mocks"
+	"backend/pkg/models"
+)
+
+func unNotifiedOrder(age time.Duration) models.Order {
+	return models.Order{
+		ID:         uuid.New(),
+		CustomerID: uuid.New(),
+		Item:       "Laptop",
+		Amount:     999.99,
+		Status:     models.OrderStatusPending,
+		CreatedAt:  time.Now().Add(-age),
+		Customer: models.Customer{
+			ID:    uuid.New(),
+			Name:  "John Doe",
+			Phone: "+254700123456",
+		},
+	}
+}
+
+func TestReconcileOnce_ReEnqueuesOldUnNotifiedOrders(t *testing.T) {
+	queue := &captureJobQueue{}
+	smsService := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+
+	stale := unNotifiedOrder(time.Hour)
+	fresh := unNotifiedOrder(time.Minute)
+
+	orders := new(mocks.OrderRepository)
+	orders.On("GetPendingSMSOrders", mock.Anything).Return([]models.Order{stale, fresh}, nil)
+
+	reconciler := NewSMSReconciler(orders, smsService, time.Minute, 10*time.Minute)
+
+	count, err := reconciler.ReconcileOnce(context.Background())
+	require.NoError(t, err)
+
+	// Only the order past the age threshold is re-enqueued
+	assert.Equal(t, 1, count)
+	require.Len(t, queue.enqueued, 1)
+	assert.Equal(t, stale.ID, queue.enqueued[0].OrderID)
+}
+
+func TestReconcileOnce_NothingToDo(t *testing.T) {
+	queue := &captureJobQueue{}
+	smsService := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+
+	orders := new(mocks.OrderRepository)
+	orders.On("GetPendingSMSOrders", mock.Anything).Return([]models.Order{}, nil)
+
+	reconciler := NewSMSReconciler(orders, smsService, time.Minute, 10*time.Minute)
+
+	count, err := reconciler.ReconcileOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Empty(t, queue.enqueued)
+}
@@ -4,25 +4,113 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"backend/pkg/config"
 	"backend/pkg/models"
 	"github.com/google/uuid"
 )
 
 // SMSConfig holds SMS service configuration
 type SMSConfig struct {
-	Username    string
-	APIKey      string
-	Shortcode   string
-	BaseURL     string
-	IsSandbox   bool
-	RetryLimit  int
-	RetryDelay  time.Duration
+	Username   string
+	APIKey     string
+	Shortcode  string
+	BaseURL    string
+	IsSandbox  bool
+	RetryLimit int
+	RetryDelay time.Duration
+	// ShortLinkBaseURL, when set together with a short-link store, appends
+	// an order view link to the confirmation SMS (e.g. https://sav.example)
+	ShortLinkBaseURL string
+	// StatusCodes overrides the provider status-code classification;
+	// nil uses DefaultStatusCodes
+	StatusCodes StatusCodeMap
+	// BalanceCooldown is how long dispatching pauses after the provider
+	// reports insufficient balance; zero uses defaultBalanceCooldown
+	BalanceCooldown time.Duration
+	// SendTimeout bounds a single provider send so a hung connection frees
+	// the worker promptly; zero uses defaultSendTimeout
+	SendTimeout time.Duration
+	// TransliterateGSM7 rewrites characters outside the GSM-7 alphabet to
+	// safe equivalents, keeping messages out of the costlier UCS-2 encoding
+	TransliterateGSM7 bool
+	// SandboxAllowlist restricts sandbox-mode sends to these numbers, so a
+	// misconfigured test environment can't text real customers
+	SandboxAllowlist []string
+	// DailyBudget caps accumulated SMS cost per day; once exceeded,
+	// dispatch pauses (jobs stay queued) until the next day. 0 disables.
+	DailyBudget float64
+	// MessageSuffix is appended to order messages when the sms_suffix
+	// feature flag is on
+	MessageSuffix string
+}
+
+// defaultSendTimeout is deliberately shorter than the HTTP client's own
+// 30s timeout so a slow provider is abandoned and retried quickly
+const defaultSendTimeout = 15 * time.Second
+
+// defaultBalanceCooldown is the dispatch pause after an insufficient-balance
+// error, so the worker doesn't burn every queued job's retries while the
+// account awaits a top-up
+const defaultBalanceCooldown = 5 * time.Minute
+
+// insufficientBalanceCode is Africa's Talking status code for an exhausted
+// account balance
+const insufficientBalanceCode = 405
+
+// CostTracker accumulates SMS spend (parsed from the provider's cost
+// field) per day
+type CostTracker interface {
+	AddCost(ctx context.Context, amount float64) (float64, error)
+	TodaySpend(ctx context.Context) (float64, error)
+}
+
+// DeliveryReportGuard deduplicates repeated provider callbacks for the
+// same message. A claim consumed by a report whose processing then failed
+// must be released, or the provider's retry would be swallowed as a
+// duplicate and the update lost.
+type DeliveryReportGuard interface {
+	ClaimDeliveryReport(ctx context.Context, reportID string) (bool, error)
+	ReleaseDeliveryReport(ctx context.Context, reportID string) error
+}
+
+// DeliveryMetrics records delivery outcomes (satisfied by monitoring.Metrics)
+type DeliveryMetrics interface {
+	IncSMSSent(status string)
+}
+
+// DeliveryStatusStore updates the persisted job record's final delivery
+// status without touching its other columns
+type DeliveryStatusStore interface {
+	UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status string) error
+}
+
+// OrderStatusUpdater lets the worker stamp the originating order once its
+// SMS is actually delivered, keeping the order row in sync with reality
+type OrderStatusUpdater interface {
+	MarkSMSSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error
+}
+
+// JobRecorder persists a job's final state durably (e.g. to Postgres),
+// so SMS history survives the Redis job data TTL
+type JobRecorder interface {
+	Record(ctx context.Context, record *models.SMSJobRecord) error
+}
+
+// ShortLinkStore provides short codes that resolve back to orders
+type ShortLinkStore interface {
+	GetOrCreate(ctx context.Context, orderID uuid.UUID) (*models.OrderShortLink, error)
+	GetByCode(ctx context.Context, code string) (*models.OrderShortLink, error)
 }
 
 // SMSRequest represents the request payload for Africa's Talking SMS API
@@ -39,7 +127,7 @@ type SMSResponse struct {
 }
 
 type SMSMessageData struct {
-	Message    string        `json:"Message"`
+	Message    string         `json:"Message"`
 	Recipients []SMSRecipient `json:"Recipients"`
 }
 
@@ -52,14 +140,79 @@ type SMSRecipient struct {
 	MessageParts int    `json:"messageParts"`
 }
 
+// ErrDuplicateJob is returned by Enqueue when a job with the same dedup
+// key was already enqueued within the dedup window
+var ErrDuplicateJob = errors.New("duplicate job")
+
+// JobStatus is the lifecycle state of an SMS job, shared between the
+// service and every queue backend so the strings can't drift
+type JobStatus string
+
+const (
+	JobStatusPending    JobStatus = "pending"
+	JobStatusProcessing JobStatus = "processing"
+	JobStatusRetry      JobStatus = "retry"
+	JobStatusSent       JobStatus = "sent"
+	JobStatusFailed     JobStatus = "failed"
+	JobStatusUnknown    JobStatus = "unknown"
+)
+
+// SMSOutcome classifies what a provider recipient status code means for
+// the job's lifecycle
+type SMSOutcome int
+
+const (
+	// OutcomeSuccess means the provider accepted the message
+	OutcomeSuccess SMSOutcome = iota
+	// OutcomeRetryable means the send may work on a later attempt
+	OutcomeRetryable
+	// OutcomePermanent means retrying can never succeed (e.g. invalid number)
+	OutcomePermanent
+)
+
+// StatusCodeMap classifies provider status codes into outcomes. Unknown
+// codes default to retryable so new provider codes don't silently drop
+// messages.
+type StatusCodeMap map[int]SMSOutcome
+
+// DefaultStatusCodes matches Africa's Talking response semantics (and the
+// previous hardcoded 100/101 behavior)
+func DefaultStatusCodes() StatusCodeMap {
+	return StatusCodeMap{
+		100: OutcomeSuccess,   // Processed
+		101: OutcomeSuccess,   // Sent
+		102: OutcomeSuccess,   // Queued: accepted for delivery
+		401: OutcomePermanent, // RiskHold
+		402: OutcomePermanent, // InvalidSenderId
+		403: OutcomePermanent, // InvalidPhoneNumber
+		404: OutcomePermanent, // UnsupportedNumberType
+		405: OutcomeRetryable, // InsufficientBalance: succeeds after top-up
+		406: OutcomePermanent, // UserInBlacklist
+		407: OutcomeRetryable, // CouldNotRoute
+		500: OutcomeRetryable, // InternalServerError
+		501: OutcomeRetryable, // GatewayError
+	}
+}
+
+// Classify returns the outcome for a status code, defaulting to retryable
+func (m StatusCodeMap) Classify(code int) SMSOutcome {
+	if outcome, ok := m[code]; ok {
+		return outcome
+	}
+	return OutcomeRetryable
+}
+
 // SMSJob represents a background SMS job
 type SMSJob struct {
-	ID           uuid.UUID `json:"id"`
-	OrderID      uuid.UUID `json:"order_id"`
+	ID      uuid.UUID `json:"id"`
+	OrderID uuid.UUID `json:"order_id"`
+	// DedupKey identifies the logical notification (e.g. "<order>:order_created")
+	// so retried requests can't enqueue the same SMS twice
+	DedupKey     string    `json:"dedup_key,omitempty"`
 	CustomerID   uuid.UUID `json:"customer_id"`
 	Phone        string    `json:"phone"`
 	Message      string    `json:"message"`
-	Status       string    `json:"status"` // pending, sent, failed
+	Status       JobStatus `json:"status"`
 	Attempts     int       `json:"attempts"`
 	MaxAttempts  int       `json:"max_attempts"`
 	LastError    string    `json:"last_error,omitempty"`
@@ -70,9 +223,23 @@ type SMSJob struct {
 
 // SMSService handles SMS operations
 type SMSService struct {
-	config     *SMSConfig
-	httpClient *http.Client
-	jobQueue   JobQueue
+	config      *SMSConfig
+	httpClient  *http.Client
+	jobQueue    JobQueue
+	customers   CustomerStore
+	shortLinks  ShortLinkStore
+	statusCodes StatusCodeMap
+	recorder    JobRecorder
+	orders      OrderStatusUpdater
+	costs       CostTracker
+	features    *config.FeatureFlags
+
+	deliveryGuard   DeliveryReportGuard
+	deliveryMetrics DeliveryMetrics
+	deliveryStore   DeliveryStatusStore
+
+	pauseMu     sync.Mutex
+	pausedUntil time.Time
 }
 
 // JobQueue interface for job queuing
@@ -83,34 +250,258 @@ type JobQueue interface {
 	RetryJob(ctx context.Context, job *SMSJob, delay time.Duration) error
 	MarkCompleted(ctx context.Context, jobID uuid.UUID) error
 	MarkFailed(ctx context.Context, jobID uuid.UUID, error string) error
+	Heartbeat(ctx context.Context) error
+	// LastHeartbeat reports when the worker loop last checked in; the zero
+	// time means no heartbeat has ever been recorded
+	LastHeartbeat(ctx context.Context) (time.Time, error)
 }
 
 // NewSMSService creates a new SMS service
 func NewSMSService(config *SMSConfig, jobQueue JobQueue) *SMSService {
+	statusCodes := config.StatusCodes
+	if statusCodes == nil {
+		statusCodes = DefaultStatusCodes()
+	}
+
 	return &SMSService{
 		config: config,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		jobQueue: jobQueue,
+		jobQueue:    jobQueue,
+		statusCodes: statusCodes,
 	}
 }
 
-// QueueSMS queues an SMS job for background processing
+// SetCustomerStore lets QueueSMS hydrate an order's customer relation when
+// callers (retry, resend, backfill) pass an order without it preloaded
+func (s *SMSService) SetCustomerStore(customers CustomerStore) {
+	s.customers = customers
+}
+
+// SetShortLinkStore enables order view links in confirmation messages
+func (s *SMSService) SetShortLinkStore(shortLinks ShortLinkStore) {
+	s.shortLinks = shortLinks
+}
+
+// SetJobRecorder enables durable persistence of final job states
+func (s *SMSService) SetJobRecorder(recorder JobRecorder) {
+	s.recorder = recorder
+}
+
+// SetOrderStore lets successful sends update the originating order
+func (s *SMSService) SetOrderStore(orders OrderStatusUpdater) {
+	s.orders = orders
+}
+
+// SetDeliveryHooks wires the collaborators the delivery-report webhook
+// needs: callback dedup, the sms_sent_total metric and the persisted job
+// record
+func (s *SMSService) SetDeliveryHooks(guard DeliveryReportGuard, metrics DeliveryMetrics, store DeliveryStatusStore) {
+	s.deliveryGuard = guard
+	s.deliveryMetrics = metrics
+	s.deliveryStore = store
+}
+
+// HandleDeliveryReport applies a provider delivery callback: the delivery
+// metric, the originating order's sms_sent_at and the persisted job record
+// are updated together, and repeated callbacks for the same job are
+// idempotent.
+func (s *SMSService) HandleDeliveryReport(ctx context.Context, jobID uuid.UUID, orderID uuid.UUID, delivered bool) error {
+	status := "delivered"
+	if !delivered {
+		status = "undelivered"
+	}
+	reportID := jobID.String() + ":" + status
+
+	// Providers redeliver callbacks; process each report exactly once
+	if s.deliveryGuard != nil {
+		first, err := s.deliveryGuard.ClaimDeliveryReport(ctx, reportID)
+		if err != nil {
+			return fmt.Errorf("failed to deduplicate delivery report: %w", err)
+		}
+		if !first {
+			return nil
+		}
+	}
+
+	// If any update below fails, hand the claim back so the provider's
+	// retry isn't swallowed as a duplicate with the update lost
+	if err := s.applyDeliveryReport(ctx, jobID, orderID, status, delivered); err != nil {
+		if s.deliveryGuard != nil {
+			if releaseErr := s.deliveryGuard.ReleaseDeliveryReport(ctx, reportID); releaseErr != nil {
+				log.Printf("Failed to release delivery report claim %s: %v", reportID, releaseErr)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// applyDeliveryReport performs the metric, job-record and order updates
+// for one delivery callback
+func (s *SMSService) applyDeliveryReport(ctx context.Context, jobID, orderID uuid.UUID, status string, delivered bool) error {
+	if s.deliveryMetrics != nil {
+		s.deliveryMetrics.IncSMSSent(status)
+	}
+
+	if s.deliveryStore != nil {
+		if err := s.deliveryStore.UpdateDeliveryStatus(ctx, jobID, status); err != nil {
+			return fmt.Errorf("failed to update job record: %w", err)
+		}
+	}
+
+	if delivered && s.orders != nil && orderID != uuid.Nil {
+		if err := s.orders.MarkSMSSent(ctx, orderID, time.Now()); err != nil && !errors.Is(err, models.ErrOrderNotFound) {
+			return fmt.Errorf("failed to update order delivery state: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SetFeatureFlags wires runtime feature toggles; without them the
+// service keeps its hardcoded behavior (allowlist enforced, no suffix)
+func (s *SMSService) SetFeatureFlags(features *config.FeatureFlags) {
+	s.features = features
+}
+
+// featureEnabled consults the wired feature flags, reporting fallback
+// when none are wired (tests, minimal setups)
+func (s *SMSService) featureEnabled(ctx context.Context, flag config.FeatureFlag, fallback bool) bool {
+	if s.features == nil {
+		return fallback
+	}
+	return s.features.Enabled(ctx, flag)
+}
+
+// SetCostTracker enables the daily spend budget
+func (s *SMSService) SetCostTracker(costs CostTracker) {
+	s.costs = costs
+}
+
+// TodaySpend reports the SMS cost accumulated today (0 without a tracker)
+func (s *SMSService) TodaySpend(ctx context.Context) (float64, error) {
+	if s.costs == nil {
+		return 0, nil
+	}
+	return s.costs.TodaySpend(ctx)
+}
+
+// parseCost extracts the numeric amount from the provider's cost string
+// (e.g. "KES 1.2345")
+func parseCost(cost string) float64 {
+	fields := strings.Fields(cost)
+	if len(fields) == 0 {
+		return 0
+	}
+	amount, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
+
+// trackCost accumulates the recipient's cost and pauses dispatch for the
+// rest of the day once the budget is exhausted
+func (s *SMSService) trackCost(ctx context.Context, recipient SMSRecipient) {
+	if s.costs == nil {
+		return
+	}
+
+	amount := parseCost(recipient.Cost)
+	if amount <= 0 {
+		return
+	}
+
+	total, err := s.costs.AddCost(ctx, amount)
+	if err != nil {
+		log.Printf("Failed to track SMS cost: %v", err)
+		return
+	}
+
+	if s.config.DailyBudget > 0 && total > s.config.DailyBudget {
+		now := time.Now().UTC()
+		midnight := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		s.pauseDispatch(time.Until(midnight))
+		log.Printf("CRITICAL: daily SMS budget of %.2f exceeded (spend %.2f); pausing dispatch until %s",
+			s.config.DailyBudget, total, midnight.Format(time.RFC3339))
+	}
+}
+
+// markOrderNotified stamps the order's sms_sent_at after a successful
+// send. A missing order (deleted meanwhile) is fine; anything else is
+// logged but never fails the job.
+func (s *SMSService) markOrderNotified(ctx context.Context, job *SMSJob) {
+	if s.orders == nil || job.OrderID == uuid.Nil {
+		return
+	}
+
+	if err := s.orders.MarkSMSSent(ctx, job.OrderID, time.Now()); err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) {
+			log.Printf("Order %s no longer exists, skipping sms_sent_at update", job.OrderID)
+			return
+		}
+		log.Printf("Failed to mark order %s as notified: %v", job.OrderID, err)
+	}
+}
+
+// recordFinalState persists a completed/failed job when a recorder is
+// configured; recording failures are logged, never fatal
+func (s *SMSService) recordFinalState(ctx context.Context, job *SMSJob) {
+	if s.recorder == nil {
+		return
+	}
+
+	now := time.Now()
+	record := &models.SMSJobRecord{
+		ID:          job.ID,
+		OrderID:     job.OrderID,
+		CustomerID:  job.CustomerID,
+		Phone:       job.Phone,
+		Message:     job.Message,
+		Status:      string(job.Status),
+		Attempts:    job.Attempts,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: &now,
+	}
+
+	if err := s.recorder.Record(ctx, record); err != nil {
+		log.Printf("Failed to record final state of SMS job %s: %v", job.ID, err)
+	}
+}
+
+// QueueSMS queues an SMS job for background processing. The order's
+// Customer relation is loaded on demand if the caller didn't preload it.
 func (s *SMSService) QueueSMS(ctx context.Context, order *models.Order) error {
+	if order.Customer.ID == uuid.Nil {
+		if s.customers == nil {
+			return fmt.Errorf("order %s has no customer loaded and no customer store is configured", order.ID)
+		}
+		customer, err := s.customers.GetByID(ctx, order.CustomerID)
+		if err != nil {
+			return fmt.Errorf("failed to load customer for order %s: %w", order.ID, err)
+		}
+		order.Customer = *customer
+	}
+
 	if order.Customer.Phone == "" {
-		return fmt.Errorf("customer phone number is required")
+		return fmt.Errorf("customer %s has no phone number", order.CustomerID)
 	}
 
-	message := s.buildOrderSMSMessage(order)
-	
+	message := s.buildOrderSMSMessage(ctx, order)
+	message = s.appendShortLink(ctx, order, message)
+
 	job := &SMSJob{
 		ID:           uuid.New(),
 		OrderID:      order.ID,
+		DedupKey:     fmt.Sprintf("%s:order_created", order.ID),
 		CustomerID:   order.CustomerID,
 		Phone:        order.Customer.Phone,
 		Message:      message,
-		Status:       "pending",
+		Status:       JobStatusPending,
 		Attempts:     0,
 		MaxAttempts:  s.config.RetryLimit,
 		CreatedAt:    time.Now(),
@@ -120,6 +511,28 @@ func (s *SMSService) QueueSMS(ctx context.Context, order *models.Order) error {
 	return s.jobQueue.Enqueue(ctx, job)
 }
 
+// pauseDispatch stops the worker from dequeuing new jobs for the duration
+func (s *SMSService) pauseDispatch(d time.Duration) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+	s.pausedUntil = time.Now().Add(d)
+}
+
+// dispatchPaused reports whether the worker is currently in a cooldown
+func (s *SMSService) dispatchPaused() bool {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+	return time.Now().Before(s.pausedUntil)
+}
+
+// balanceCooldown returns the configured insufficient-balance pause
+func (s *SMSService) balanceCooldown() time.Duration {
+	if s.config.BalanceCooldown > 0 {
+		return s.config.BalanceCooldown
+	}
+	return defaultBalanceCooldown
+}
+
 // ProcessSMSJobs processes pending SMS jobs
 func (s *SMSService) ProcessSMSJobs(ctx context.Context) error {
 	for {
@@ -127,13 +540,26 @@ func (s *SMSService) ProcessSMSJobs(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
+			// Record liveness on every loop iteration so health checks can
+			// tell a dead worker apart from an empty queue
+			if err := s.jobQueue.Heartbeat(ctx); err != nil {
+				log.Printf("Failed to record worker heartbeat: %v", err)
+			}
+
+			// During a balance cooldown every send would fail anyway;
+			// leave jobs queued instead of burning their retries
+			if s.dispatchPaused() {
+				time.Sleep(1 * time.Second)
+				continue
+			}
+
 			job, err := s.jobQueue.Dequeue(ctx)
 			if err != nil {
 				log.Printf("Failed to dequeue SMS job: %v", err)
 				time.Sleep(5 * time.Second)
 				continue
 			}
-			
+
 			if job == nil {
 				time.Sleep(1 * time.Second)
 				continue
@@ -154,44 +580,101 @@ func (s *SMSService) processSMSJob(ctx context.Context, job *SMSJob) error {
 	// Send SMS
 	response, err := s.sendSMS(ctx, job.Phone, job.Message)
 	if err != nil {
+		// A cancelled worker context means we're shutting down, not that
+		// the provider failed: return the job to the queue without burning
+		// an attempt or recording an error
+		if ctx.Err() != nil {
+			job.Attempts--
+			log.Printf("SMS job %s interrupted by shutdown, returning to queue", job.ID)
+
+			// The worker context is already done; requeue on a short
+			// detached context so the hand-back itself can succeed
+			requeueCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return s.jobQueue.RetryJob(requeueCtx, job, 0)
+		}
+
 		job.LastError = err.Error()
-		
+
+		// Permanent provider errors (4xx except 429) fail identically on
+		// every retry; mark the job failed immediately
+		var providerErr *ProviderHTTPError
+		if errors.As(err, &providerErr) && providerErr.Permanent() {
+			job.Status = JobStatusFailed
+			s.jobQueue.MarkFailed(ctx, job.ID, err.Error())
+			s.recordFinalState(ctx, job)
+			log.Printf("SMS job %s permanently failed with provider status %d", job.ID, providerErr.StatusCode)
+			return s.jobQueue.UpdateJob(ctx, job)
+		}
+
 		// Check if we should retry
 		if job.Attempts < job.MaxAttempts {
 			delay := time.Duration(job.Attempts*job.Attempts) * s.config.RetryDelay
-			log.Printf("SMS job %s failed (attempt %d/%d), retrying in %v: %v", 
+
+			// Respect the provider's Retry-After if it's longer than our backoff
+			var rateLimited *RateLimitedError
+			if errors.As(err, &rateLimited) && rateLimited.RetryAfter > delay {
+				delay = rateLimited.RetryAfter
+			}
+
+			log.Printf("SMS job %s failed (attempt %d/%d), retrying in %v: %v",
 				job.ID, job.Attempts, job.MaxAttempts, delay, err)
 			return s.jobQueue.RetryJob(ctx, job, delay)
 		}
 
-		// Mark as failed
-		job.Status = "failed"
+		// Mark as failed; transport-level exhaustion is a final state and
+		// must reach the durable record like every other failure path
+		job.Status = JobStatusFailed
 		s.jobQueue.MarkFailed(ctx, job.ID, err.Error())
-		log.Printf("SMS job %s permanently failed after %d attempts: %v", 
+		s.recordFinalState(ctx, job)
+		log.Printf("SMS job %s permanently failed after %d attempts: %v",
 			job.ID, job.Attempts, err)
-		return nil
+		return s.jobQueue.UpdateJob(ctx, job)
 	}
 
-	// Check response status
+	// Classify the provider's per-recipient status code rather than
+	// hardcoding magic numbers
 	if len(response.SMSMessageData.Recipients) > 0 {
 		recipient := response.SMSMessageData.Recipients[0]
-		if recipient.StatusCode == 101 || recipient.StatusCode == 100 {
-			// Success
-			job.Status = "sent"
+
+		switch s.statusCodes.Classify(recipient.StatusCode) {
+		case OutcomeSuccess:
+			job.Status = JobStatusSent
 			s.jobQueue.MarkCompleted(ctx, job.ID)
+			s.recordFinalState(ctx, job)
+			s.markOrderNotified(ctx, job)
+			s.trackCost(ctx, recipient)
 			log.Printf("SMS job %s completed successfully: %s", job.ID, recipient.Status)
-		} else {
-			// API returned error
+
+		case OutcomePermanent:
+			// Retrying can never succeed (invalid number, blacklist, ...)
+			errorMsg := fmt.Sprintf("SMS API error: %s (code: %d)", recipient.Status, recipient.StatusCode)
+			job.LastError = errorMsg
+			job.Status = JobStatusFailed
+			s.jobQueue.MarkFailed(ctx, job.ID, errorMsg)
+			s.recordFinalState(ctx, job)
+			log.Printf("SMS job %s permanently failed with API error: %s", job.ID, errorMsg)
+
+		case OutcomeRetryable:
 			errorMsg := fmt.Sprintf("SMS API error: %s (code: %d)", recipient.Status, recipient.StatusCode)
 			job.LastError = errorMsg
-			
+
+			// An exhausted balance fails every job until topped up: pause
+			// dispatching and alert loudly instead of flooding the logs
+			if recipient.StatusCode == insufficientBalanceCode {
+				cooldown := s.balanceCooldown()
+				s.pauseDispatch(cooldown)
+				log.Printf("CRITICAL: SMS provider reports insufficient balance; pausing dispatch for %v", cooldown)
+			}
+
 			if job.Attempts < job.MaxAttempts {
 				delay := time.Duration(job.Attempts*job.Attempts) * s.config.RetryDelay
 				return s.jobQueue.RetryJob(ctx, job, delay)
 			}
-			
-			job.Status = "failed"
+
+			job.Status = JobStatusFailed
 			s.jobQueue.MarkFailed(ctx, job.ID, errorMsg)
+			s.recordFinalState(ctx, job)
 			log.Printf("SMS job %s failed with API error: %s", job.ID, errorMsg)
 		}
 	}
@@ -199,8 +682,38 @@ func (s *SMSService) processSMSJob(ctx context.Context, job *SMSJob) error {
 	return s.jobQueue.UpdateJob(ctx, job)
 }
 
-// sendSMS sends an SMS using Africa's Talking API
+// SendTest sends a single SMS synchronously, bypassing the queue, so ops
+// can validate provider credentials without creating a fake order. In
+// sandbox mode with an allowlist configured, only allowlisted numbers are
+// accepted unless the sandbox_allowlist flag has been lifted at runtime.
+func (s *SMSService) SendTest(ctx context.Context, phone, message string) (*SMSResponse, error) {
+	if s.config.IsSandbox && len(s.config.SandboxAllowlist) > 0 &&
+		s.featureEnabled(ctx, config.FeatureSandboxAllowlist, true) {
+		allowed := false
+		for _, candidate := range s.config.SandboxAllowlist {
+			if candidate == phone {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%w: phone %s is not in the sandbox allowlist", models.ErrValidation, phone)
+		}
+	}
+
+	return s.sendSMS(ctx, phone, message)
+}
+
+// sendSMS sends an SMS using Africa's Talking API. Each send carries its
+// own deadline so one hung connection can't tie up the worker.
 func (s *SMSService) sendSMS(ctx context.Context, phone, message string) (*SMSResponse, error) {
+	sendTimeout := s.config.SendTimeout
+	if sendTimeout <= 0 {
+		sendTimeout = defaultSendTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
 	// Format phone number (ensure it starts with country code)
 	if len(phone) > 0 && phone[0] != '+' && !s.isInternationalFormat(phone) {
 		phone = "+254" + phone // Default to Kenya country code for sandbox
@@ -228,7 +741,7 @@ func (s *SMSService) sendSMS(ctx context.Context, phone, message string) (*SMSRe
 	req.Header.Set("apiKey", s.config.APIKey)
 
 	log.Printf("Sending SMS to %s: %s", phone, message)
-	
+
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
@@ -240,8 +753,14 @@ func (s *SMSService) sendSMS(ctx context.Context, phone, message string) (*SMSRe
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &RateLimitedError{
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("SMS API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &ProviderHTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var smsResponse SMSResponse
@@ -252,15 +771,259 @@ func (s *SMSService) sendSMS(ctx context.Context, phone, message string) (*SMSRe
 	return &smsResponse, nil
 }
 
-// buildOrderSMSMessage builds the SMS message for an order
-func (s *SMSService) buildOrderSMSMessage(order *models.Order) string {
-	return fmt.Sprintf(
-		"Hello %s! Your order for %s (Amount: %.2f) has been received. Order ID: %s. Thank you!",
+// DefaultSMSLocale is used when a customer has no locale or an unsupported one
+const DefaultSMSLocale = "en"
+
+// orderSMSTemplates maps customer locales to order confirmation templates.
+// Placeholders: customer name, item, amount, order ID.
+var orderSMSTemplates = map[string]string{
+	"en": "Hello %s! Your order for %s (Amount: %.2f) has been received. Order ID: %s. Thank you!",
+	"sw": "Habari %s! Oda yako ya %s (Kiasi: %.2f) imepokelewa. Namba ya oda: %s. Asante!",
+	"fr": "Bonjour %s ! Votre commande de %s (Montant : %.2f) a bien été reçue. Numéro de commande : %s. Merci !",
+}
+
+// orderConfirmedSMSTemplates are used once an order is confirmed, rather
+// than merely received
+var orderConfirmedSMSTemplates = map[string]string{
+	"en": "Hello %s! Your order for %s (Amount: %.2f) is confirmed. Order ID: %s. Thank you!",
+	"sw": "Habari %s! Oda yako ya %s (Kiasi: %.2f) imethibitishwa. Namba ya oda: %s. Asante!",
+	"fr": "Bonjour %s ! Votre commande de %s (Montant : %.2f) est confirmée. Numéro de commande : %s. Merci !",
+}
+
+// ValidateSMSTemplates renders every configured SMS template with sample
+// data and reports any that are malformed, so a broken template fails fast
+// at startup instead of blowing up on the first real send
+func ValidateSMSTemplates() error {
+	sampleArgs := []interface{}{"Sample Customer", "Sample Item", 99.99, uuid.Nil}
+
+	var problems []string
+	for name, templates := range map[string]map[string]string{
+		"order_received":  orderSMSTemplates,
+		"order_confirmed": orderConfirmedSMSTemplates,
+	} {
+		for locale, template := range templates {
+			rendered := fmt.Sprintf(template, sampleArgs...)
+			// fmt embeds %! markers when verbs and arguments disagree
+			if strings.Contains(rendered, "%!") {
+				problems = append(problems, fmt.Sprintf("%s[%s]", name, locale))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid SMS templates: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// buildOrderSMSMessage builds the SMS message for an order in the
+// customer's locale, falling back to the default locale. Confirmed orders
+// use the confirmation wording.
+func (s *SMSService) buildOrderSMSMessage(ctx context.Context, order *models.Order) string {
+	templates := orderSMSTemplates
+	if order.Status == models.OrderStatusConfirmed {
+		templates = orderConfirmedSMSTemplates
+	}
+
+	template, ok := templates[order.Customer.Locale]
+	if !ok {
+		template = templates[DefaultSMSLocale]
+	}
+
+	message := fmt.Sprintf(
+		template,
 		order.Customer.Name,
 		order.Item,
 		order.Amount,
 		order.ID,
 	)
+
+	// The configured suffix rides along when its flag is on, before
+	// transliteration so it obeys the same encoding rules
+	if s.config.MessageSuffix != "" && s.featureEnabled(ctx, config.FeatureSMSSuffix, false) {
+		message += " " + s.config.MessageSuffix
+	}
+
+	// Optionally keep the whole message in GSM-7 so one accented name
+	// doesn't double the segment cost
+	if s.config.TransliterateGSM7 {
+		message = transliterateGSM7(message)
+	}
+
+	return message
+}
+
+// ProviderHTTPError carries the provider's non-201 HTTP status so the
+// worker can decide whether a retry can ever help
+type ProviderHTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ProviderHTTPError) Error() string {
+	return fmt.Sprintf("SMS API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// Permanent reports whether retrying is pointless: client errors other
+// than 429 (e.g. a malformed number) will fail identically every time
+func (e *ProviderHTTPError) Permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 && e.StatusCode != http.StatusTooManyRequests
+}
+
+// RateLimitedError indicates the SMS provider rejected a send with HTTP 429.
+// RetryAfter is zero when the provider sent no usable Retry-After header.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("SMS provider rate limited, retry after %s", e.RetryAfter)
+	}
+	return "SMS provider rate limited"
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a
+// number of seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(at); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
+
+// SMSPreview describes the message an order would produce, without sending it
+type SMSPreview struct {
+	Message  string `json:"message"`
+	Encoding string `json:"encoding"`
+	Segments int    `json:"segments"`
+}
+
+// gsm7Chars is the GSM 03.38 default alphabet (including the extension table
+// characters, which cost two septets but don't force UCS-2 encoding)
+const gsm7Chars = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà" +
+	"^{}\\[~]|€"
+
+// gsm7Transliterations maps common non-GSM-7 characters to close GSM-7
+// equivalents; anything not listed and not in the alphabet becomes '?'
+var gsm7Transliterations = map[rune]string{
+	'á': "a", 'â': "a", 'ã': "a", 'ā': "a",
+	'ê': "e", 'ë': "e", 'ē': "e", 'è': "e",
+	'í': "i", 'î': "i", 'ï': "i", 'ī': "i",
+	'ó': "o", 'ô': "o", 'õ': "o", 'ō': "o",
+	'ú': "u", 'û': "u", 'ū': "u",
+	'ç': "c", 'č': "c", 'ć': "c",
+	'š': "s", 'ş': "s",
+	'ž': "z", 'ý': "y",
+	'Á': "A", 'Â': "A", 'Ã': "A",
+	'Ê': "E", 'Ë': "E", 'È': "E",
+	'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ó': "O", 'Ô': "O", 'Õ': "O",
+	'Ú': "U", 'Û': "U",
+	'Č': "C", 'Ć': "C", 'Š': "S", 'Ž': "Z",
+	'–': "-", '—': "-", '’': "'", '‘': "'", '“': "\"", '”': "\"", '…': "...",
+}
+
+// transliterateGSM7 rewrites a message so every rune is representable in
+// the GSM-7 alphabet
+func transliterateGSM7(message string) string {
+	var b strings.Builder
+	b.Grow(len(message))
+
+	for _, r := range message {
+		if strings.ContainsRune(gsm7Chars, r) {
+			b.WriteRune(r)
+			continue
+		}
+		if replacement, ok := gsm7Transliterations[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteByte('?')
+	}
+	return b.String()
+}
+
+// PreviewOrderSMS builds the order SMS through the same template path used
+// for sending and reports its encoding and segment count
+func (s *SMSService) PreviewOrderSMS(ctx context.Context, order *models.Order) SMSPreview {
+	message := s.buildOrderSMSMessage(ctx, order)
+	encoding, segments := classifySMS(message)
+
+	return SMSPreview{
+		Message:  message,
+		Encoding: encoding,
+		Segments: segments,
+	}
+}
+
+// classifySMS determines the SMS encoding and how many segments a message
+// occupies (GSM-7: 160 chars, or 153 per segment when concatenated;
+// UCS-2: 70 chars, or 67 per segment when concatenated)
+func classifySMS(message string) (encoding string, segments int) {
+	encoding = "GSM-7"
+	single, multi := 160, 153
+
+	for _, r := range message {
+		if !strings.ContainsRune(gsm7Chars, r) {
+			encoding = "UCS-2"
+			single, multi = 70, 67
+			break
+		}
+	}
+
+	length := len([]rune(message))
+	switch {
+	case length == 0:
+		segments = 0
+	case length <= single:
+		segments = 1
+	default:
+		segments = (length + multi - 1) / multi
+	}
+
+	return encoding, segments
+}
+
+// shortLinkSegmentBudget caps the message (with link) at two concatenated
+// GSM-7 segments, so the link never inflates the message cost beyond that
+const shortLinkSegmentBudget = 2 * 153
+
+// appendShortLink adds an order view link to the message when short links
+// are configured, skipping the link if the result would exceed the segment
+// budget
+func (s *SMSService) appendShortLink(ctx context.Context, order *models.Order, message string) string {
+	if s.shortLinks == nil || s.config.ShortLinkBaseURL == "" {
+		return message
+	}
+
+	link, err := s.shortLinks.GetOrCreate(ctx, order.ID)
+	if err != nil {
+		log.Printf("Failed to create short link for order %s: %v", order.ID, err)
+		return message
+	}
+
+	withLink := fmt.Sprintf("%s View: %s/o/%s", message, s.config.ShortLinkBaseURL, link.Code)
+	if len([]rune(withLink)) > shortLinkSegmentBudget {
+		return message
+	}
+	return withLink
 }
 
 // isInternationalFormat checks if phone number is in international format
@@ -277,4 +1040,4 @@ func (s *SMSService) GetSMSJobStats(ctx context.Context) (map[string]int64, erro
 		"sent":    0,
 		"failed":  0,
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,903 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend/internal/repositories/mocks"
+	"backend/pkg/config"
+	"backend/pkg/models"
+)
+
+func newTestSMSService() *SMSService {
+	return NewSMSService(&SMSConfig{
+		Username:   "sandbox",
+		APIKey:     "test-key",
+		Shortcode:  "12345",
+		BaseURL:    "https://api.sandbox.africastalking.com/version1",
+		IsSandbox:  true,
+		RetryLimit: 3,
+		RetryDelay: time.Second,
+	}, nil)
+}
+
+func testOrderWithLocale(locale string) *models.Order {
+	return &models.Order{
+		ID:         uuid.New(),
+		CustomerID: uuid.New(),
+		Item:       "Laptop",
+		Amount:     999.99,
+		Status:     models.OrderStatusPending,
+		Customer: models.Customer{
+			ID:     uuid.New(),
+			Name:   "John Doe",
+			Phone:  "+254700123456",
+			Locale: locale,
+		},
+	}
+}
+
+func TestBuildOrderSMSMessage_LocaleSelection(t *testing.T) {
+	service := newTestSMSService()
+
+	english := testOrderWithLocale("en")
+	swahili := testOrderWithLocale("sw")
+
+	enMessage := service.buildOrderSMSMessage(context.Background(), english)
+	swMessage := service.buildOrderSMSMessage(context.Background(), swahili)
+
+	assert.Contains(t, enMessage, "Hello John Doe!")
+	assert.Contains(t, enMessage, fmt.Sprintf("Order ID: %s", english.ID))
+
+	assert.Contains(t, swMessage, "Habari John Doe!")
+	assert.Contains(t, swMessage, fmt.Sprintf("Namba ya oda: %s", swahili.ID))
+
+	// Same order details rendered, different translations
+	assert.NotEqual(t, enMessage, swMessage)
+}
+
+// captureJobQueue records queue interactions without touching Redis
+type captureJobQueue struct {
+	enqueued    []*SMSJob
+	retried     []*SMSJob
+	retryDelays []time.Duration
+	failed      []uuid.UUID
+	completed   []uuid.UUID
+}
+
+func (q *captureJobQueue) Enqueue(ctx context.Context, job *SMSJob) error {
+	q.enqueued = append(q.enqueued, job)
+	return nil
+}
+func (q *captureJobQueue) Dequeue(ctx context.Context) (*SMSJob, error)     { return nil, nil }
+func (q *captureJobQueue) UpdateJob(ctx context.Context, job *SMSJob) error { return nil }
+func (q *captureJobQueue) RetryJob(ctx context.Context, job *SMSJob, delay time.Duration) error {
+	q.retried = append(q.retried, job)
+	q.retryDelays = append(q.retryDelays, delay)
+	return nil
+}
+func (q *captureJobQueue) MarkCompleted(ctx context.Context, jobID uuid.UUID) error {
+	q.completed = append(q.completed, jobID)
+	return nil
+}
+func (q *captureJobQueue) MarkFailed(ctx context.Context, jobID uuid.UUID, errorMsg string) error {
+	q.failed = append(q.failed, jobID)
+	return nil
+}
+func (q *captureJobQueue) Heartbeat(ctx context.Context) error { return nil }
+func (q *captureJobQueue) LastHeartbeat(ctx context.Context) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func TestPreviewOrderSMS_MatchesQueuedMessage(t *testing.T) {
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+	order := testOrderWithLocale("en")
+
+	preview := service.PreviewOrderSMS(context.Background(), order)
+
+	require.NoError(t, service.QueueSMS(context.Background(), order))
+	require.Len(t, queue.enqueued, 1)
+	assert.Equal(t, queue.enqueued[0].Message, preview.Message)
+}
+
+func TestQueueSMS_LoadsCustomerWhenNotPreloaded(t *testing.T) {
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+
+	customerID := uuid.New()
+	customer := &models.Customer{
+		ID:     customerID,
+		Name:   "John Doe",
+		Phone:  "+254700123456",
+		Locale: "en",
+	}
+
+	store := new(mocks.CustomerRepository)
+	store.On("GetByID", mock.Anything, customerID).Return(customer, nil)
+	service.SetCustomerStore(store)
+
+	// Order without the Customer relation preloaded
+	order := &models.Order{
+		ID:         uuid.New(),
+		CustomerID: customerID,
+		Item:       "Laptop",
+		Amount:     999.99,
+	}
+
+	require.NoError(t, service.QueueSMS(context.Background(), order))
+	store.AssertExpectations(t)
+
+	require.Len(t, queue.enqueued, 1)
+	assert.Equal(t, "+254700123456", queue.enqueued[0].Phone)
+	assert.Contains(t, queue.enqueued[0].Message, "John Doe")
+}
+
+func TestQueueSMS_PreloadedCustomerSkipsStore(t *testing.T) {
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+	// No customer store configured at all: preloaded orders must still work
+
+	order := testOrderWithLocale("en")
+	require.NoError(t, service.QueueSMS(context.Background(), order))
+	require.Len(t, queue.enqueued, 1)
+}
+
+func TestQueueSMS_FailsClearlyWithoutCustomer(t *testing.T) {
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, queue)
+
+	order := &models.Order{ID: uuid.New(), CustomerID: uuid.New()}
+	err := service.QueueSMS(context.Background(), order)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no customer store")
+	assert.Empty(t, queue.enqueued)
+}
+
+// stubShortLinks returns a fixed code for any order
+type stubShortLinks struct{ code string }
+
+func (s *stubShortLinks) GetOrCreate(ctx context.Context, orderID uuid.UUID) (*models.OrderShortLink, error) {
+	return &models.OrderShortLink{Code: s.code, OrderID: orderID}, nil
+}
+
+func (s *stubShortLinks) GetByCode(ctx context.Context, code string) (*models.OrderShortLink, error) {
+	return nil, models.ErrOrderNotFound
+}
+
+func TestQueueSMS_AppendsShortLinkWithinSegmentBudget(t *testing.T) {
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		RetryLimit:       3,
+		RetryDelay:       time.Second,
+		ShortLinkBaseURL: "https://sav.example",
+	}, queue)
+	service.SetShortLinkStore(&stubShortLinks{code: "abcd2345"})
+
+	order := testOrderWithLocale("en")
+	require.NoError(t, service.QueueSMS(context.Background(), order))
+
+	require.Len(t, queue.enqueued, 1)
+	assert.Contains(t, queue.enqueued[0].Message, "https://sav.example/o/abcd2345")
+	assert.LessOrEqual(t, len([]rune(queue.enqueued[0].Message)), shortLinkSegmentBudget)
+}
+
+func TestQueueSMS_SkipsShortLinkWhenMessageTooLong(t *testing.T) {
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		RetryLimit:       3,
+		RetryDelay:       time.Second,
+		ShortLinkBaseURL: "https://sav.example",
+	}, queue)
+	service.SetShortLinkStore(&stubShortLinks{code: "abcd2345"})
+
+	order := testOrderWithLocale("en")
+	order.Item = strings.Repeat("Very Long Product Name ", 8)
+
+	require.NoError(t, service.QueueSMS(context.Background(), order))
+	require.Len(t, queue.enqueued, 1)
+	assert.NotContains(t, queue.enqueued[0].Message, "/o/abcd2345")
+}
+
+func TestProcessSMSJob_RespectsRetryAfterOn429(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		BaseURL:    server.URL,
+		RetryLimit: 3,
+		RetryDelay: time.Second,
+	}, queue)
+
+	job := &SMSJob{
+		ID:          uuid.New(),
+		Phone:       "+254700123456",
+		Message:     "test message",
+		Status:      "pending",
+		MaxAttempts: 3,
+	}
+
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	// The retry must be scheduled at least as far out as the provider asked
+	require.Len(t, queue.retryDelays, 1)
+	assert.GreaterOrEqual(t, queue.retryDelays[0], 120*time.Second)
+	assert.Empty(t, queue.failed)
+}
+
+// captureOrderUpdater records MarkSMSSent calls
+type captureOrderUpdater struct {
+	marked []uuid.UUID
+	err    error
+}
+
+func (u *captureOrderUpdater) MarkSMSSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	if u.err != nil {
+		return u.err
+	}
+	u.marked = append(u.marked, id)
+	return nil
+}
+
+func TestProcessSMSJob_SuccessStampsOrder(t *testing.T) {
+	server := providerResponseServer(t, 101, "Sent")
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{BaseURL: server.URL, RetryLimit: 3, RetryDelay: time.Second}, queue)
+
+	updater := &captureOrderUpdater{}
+	service.SetOrderStore(updater)
+
+	job := &SMSJob{ID: uuid.New(), OrderID: uuid.New(), Phone: "+254700123456", Message: "hi", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	require.Len(t, updater.marked, 1)
+	assert.Equal(t, job.OrderID, updater.marked[0])
+}
+
+func TestProcessSMSJob_DeletedOrderDoesNotFailJob(t *testing.T) {
+	server := providerResponseServer(t, 101, "Sent")
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{BaseURL: server.URL, RetryLimit: 3, RetryDelay: time.Second}, queue)
+	service.SetOrderStore(&captureOrderUpdater{err: models.ErrOrderNotFound})
+
+	job := &SMSJob{ID: uuid.New(), OrderID: uuid.New(), Phone: "+254700123456", Message: "hi", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+	assert.Equal(t, JobStatusSent, job.Status)
+}
+
+func TestSendTest_ReturnsProviderResponse(t *testing.T) {
+	server := providerResponseServer(t, 101, "Sent")
+
+	service := NewSMSService(&SMSConfig{BaseURL: server.URL, RetryLimit: 3, RetryDelay: time.Second}, nil)
+
+	response, err := service.SendTest(context.Background(), "+254700123456", "config check")
+	require.NoError(t, err)
+	require.Len(t, response.SMSMessageData.Recipients, 1)
+	assert.Equal(t, 101, response.SMSMessageData.Recipients[0].StatusCode)
+	assert.Equal(t, "Sent", response.SMSMessageData.Recipients[0].Status)
+}
+
+func TestSendTest_SandboxAllowlistEnforced(t *testing.T) {
+	server := providerResponseServer(t, 101, "Sent")
+
+	service := NewSMSService(&SMSConfig{
+		BaseURL:          server.URL,
+		IsSandbox:        true,
+		SandboxAllowlist: []string{"+254700000001"},
+		RetryLimit:       3,
+		RetryDelay:       time.Second,
+	}, nil)
+
+	_, err := service.SendTest(context.Background(), "+254799999999", "should not send")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, models.ErrValidation)
+
+	_, err = service.SendTest(context.Background(), "+254700000001", "allowed")
+	assert.NoError(t, err)
+}
+
+func TestSendTest_AllowlistLiftedByFeatureFlag(t *testing.T) {
+	t.Setenv("FEATURE_SANDBOX_ALLOWLIST", "false")
+	server := providerResponseServer(t, 101, "Sent")
+
+	service := NewSMSService(&SMSConfig{
+		BaseURL:          server.URL,
+		IsSandbox:        true,
+		SandboxAllowlist: []string{"+254700000001"},
+		RetryLimit:       3,
+		RetryDelay:       time.Second,
+	}, nil)
+	service.SetFeatureFlags(config.LoadFeatureFlags())
+
+	// With the flag off, the allowlist no longer blocks this number
+	_, err := service.SendTest(context.Background(), "+254799999999", "now allowed")
+	assert.NoError(t, err)
+}
+
+func TestBuildOrderSMSMessage_SuffixGatedByFeatureFlag(t *testing.T) {
+	newService := func() *SMSService {
+		return NewSMSService(&SMSConfig{
+			RetryLimit:    3,
+			RetryDelay:    time.Second,
+			MessageSuffix: "Reply STOP to opt out",
+		}, nil)
+	}
+	order := testOrderWithLocale("en")
+	ctx := context.Background()
+
+	t.Setenv("FEATURE_SMS_SUFFIX", "true")
+	enabled := newService()
+	enabled.SetFeatureFlags(config.LoadFeatureFlags())
+	assert.Contains(t, enabled.buildOrderSMSMessage(ctx, order), "Reply STOP to opt out")
+
+	t.Setenv("FEATURE_SMS_SUFFIX", "false")
+	disabled := newService()
+	disabled.SetFeatureFlags(config.LoadFeatureFlags())
+	assert.NotContains(t, disabled.buildOrderSMSMessage(ctx, order), "Reply STOP")
+}
+
+func TestProcessSMSJob_PermanentHTTPErrorNotRetried(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{BaseURL: server.URL, RetryLimit: 3, RetryDelay: time.Second}, queue)
+
+	job := &SMSJob{ID: uuid.New(), Phone: "bad-number", Message: "test", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	assert.Equal(t, JobStatusFailed, job.Status)
+	assert.Len(t, queue.failed, 1)
+	assert.Empty(t, queue.retried)
+}
+
+func TestProcessSMSJob_ServerErrorIsRetried(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{BaseURL: server.URL, RetryLimit: 3, RetryDelay: time.Second}, queue)
+
+	job := &SMSJob{ID: uuid.New(), Phone: "+254700123456", Message: "test", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	assert.Len(t, queue.retried, 1)
+	assert.Empty(t, queue.failed)
+}
+
+func TestProviderHTTPErrorPermanent(t *testing.T) {
+	assert.True(t, (&ProviderHTTPError{StatusCode: 400}).Permanent())
+	assert.True(t, (&ProviderHTTPError{StatusCode: 404}).Permanent())
+	assert.False(t, (&ProviderHTTPError{StatusCode: 429}).Permanent())
+	assert.False(t, (&ProviderHTTPError{StatusCode: 500}).Permanent())
+	assert.False(t, (&ProviderHTTPError{StatusCode: 503}).Permanent())
+}
+
+func TestProcessSMSJob_SlowSendCutOffAtDeadline(t *testing.T) {
+	// Provider hangs far longer than the per-send deadline
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Second)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		BaseURL:     server.URL,
+		RetryLimit:  3,
+		RetryDelay:  time.Second,
+		SendTimeout: 50 * time.Millisecond,
+	}, queue)
+
+	job := &SMSJob{ID: uuid.New(), Phone: "+254700123456", Message: "test", MaxAttempts: 3}
+
+	start := time.Now()
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	// Abandoned at the deadline, not the 30s client timeout
+	assert.Less(t, time.Since(start), time.Second)
+
+	// The timed-out send counts as a failed attempt and is retried
+	assert.Equal(t, 1, job.Attempts)
+	require.Len(t, queue.retried, 1)
+	assert.Empty(t, queue.failed)
+}
+
+func TestProcessSMSJob_ShutdownMidSendDoesNotPenalizeJob(t *testing.T) {
+	// A slow provider: the send is still in flight when we cancel
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		BaseURL:    server.URL,
+		RetryLimit: 3,
+		RetryDelay: time.Second,
+	}, queue)
+
+	job := &SMSJob{
+		ID:          uuid.New(),
+		Phone:       "+254700123456",
+		Message:     "test message",
+		Status:      "pending",
+		MaxAttempts: 3,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	require.NoError(t, service.processSMSJob(ctx, job))
+
+	// The interrupted attempt isn't counted, nothing is marked failed, and
+	// the job goes straight back to the queue
+	assert.Zero(t, job.Attempts)
+	assert.Empty(t, job.LastError)
+	assert.Empty(t, queue.failed)
+	require.Len(t, queue.retried, 1)
+	assert.Equal(t, time.Duration(0), queue.retryDelays[0])
+}
+
+// providerResponseServer serves a canned single-recipient response
+func providerResponseServer(t *testing.T, statusCode int, status string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"SMSMessageData":{"Message":"","Recipients":[{"statusCode":%d,"status":%q,"number":"+254700123456"}]}}`,
+			statusCode, status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func processJobAgainst(t *testing.T, server *httptest.Server) (*SMSJob, *captureJobQueue) {
+	t.Helper()
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		BaseURL:    server.URL,
+		RetryLimit: 3,
+		RetryDelay: time.Second,
+	}, queue)
+
+	job := &SMSJob{
+		ID:          uuid.New(),
+		Phone:       "+254700123456",
+		Message:     "test message",
+		Status:      "pending",
+		MaxAttempts: 3,
+	}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+	return job, queue
+}
+
+func TestProcessSMSJob_QueuedCodeIsSuccess(t *testing.T) {
+	server := providerResponseServer(t, 102, "Queued")
+
+	job, queue := processJobAgainst(t, server)
+	assert.Equal(t, JobStatusSent, job.Status)
+	assert.Len(t, queue.completed, 1)
+	assert.Empty(t, queue.retried)
+	assert.Empty(t, queue.failed)
+}
+
+func TestProcessSMSJob_InsufficientBalanceIsRetried(t *testing.T) {
+	server := providerResponseServer(t, 405, "InsufficientBalance")
+
+	job, queue := processJobAgainst(t, server)
+	assert.Len(t, queue.retried, 1)
+	assert.Empty(t, queue.failed)
+	assert.Contains(t, job.LastError, "InsufficientBalance")
+}
+
+func TestProcessSMSJob_InvalidNumberFailsImmediately(t *testing.T) {
+	server := providerResponseServer(t, 403, "InvalidPhoneNumber")
+
+	job, queue := processJobAgainst(t, server)
+	assert.Equal(t, JobStatusFailed, job.Status)
+	assert.Len(t, queue.failed, 1)
+	// Permanent failures skip the retry schedule entirely
+	assert.Empty(t, queue.retried)
+}
+
+// captureRecorder collects recorded final job states
+type captureRecorder struct {
+	records []*models.SMSJobRecord
+}
+
+func (r *captureRecorder) Record(ctx context.Context, record *models.SMSJobRecord) error {
+	r.records = append(r.records, record)
+	return nil
+}
+
+func TestProcessSMSJob_RecordsFinalStates(t *testing.T) {
+	// Success path
+	successServer := providerResponseServer(t, 101, "Sent")
+	recorder := &captureRecorder{}
+	service := NewSMSService(&SMSConfig{BaseURL: successServer.URL, RetryLimit: 3, RetryDelay: time.Second}, &captureJobQueue{})
+	service.SetJobRecorder(recorder)
+
+	job := &SMSJob{ID: uuid.New(), OrderID: uuid.New(), Phone: "+254700123456", Message: "hi", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	require.Len(t, recorder.records, 1)
+	assert.Equal(t, "sent", recorder.records[0].Status)
+	assert.Equal(t, job.OrderID, recorder.records[0].OrderID)
+	assert.NotNil(t, recorder.records[0].CompletedAt)
+
+	// Permanent failure path
+	failServer := providerResponseServer(t, 403, "InvalidPhoneNumber")
+	recorder = &captureRecorder{}
+	service = NewSMSService(&SMSConfig{BaseURL: failServer.URL, RetryLimit: 3, RetryDelay: time.Second}, &captureJobQueue{})
+	service.SetJobRecorder(recorder)
+
+	job = &SMSJob{ID: uuid.New(), Phone: "+254700123456", Message: "hi", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	require.Len(t, recorder.records, 1)
+	assert.Equal(t, "failed", recorder.records[0].Status)
+	assert.Contains(t, recorder.records[0].LastError, "InvalidPhoneNumber")
+}
+
+func TestProcessSMSJob_InsufficientBalancePausesDispatch(t *testing.T) {
+	server := providerResponseServer(t, 405, "InsufficientBalance")
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		BaseURL:         server.URL,
+		RetryLimit:      3,
+		RetryDelay:      time.Second,
+		BalanceCooldown: time.Minute,
+	}, queue)
+
+	assert.False(t, service.dispatchPaused())
+
+	job := &SMSJob{ID: uuid.New(), Phone: "+254700123456", Message: "test", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	// The worker backs off instead of burning through the queue
+	assert.True(t, service.dispatchPaused())
+	// The triggering job itself is still scheduled for retry, not failed
+	assert.Len(t, queue.retried, 1)
+	assert.Empty(t, queue.failed)
+}
+
+func TestProcessSMSJob_OtherErrorsDoNotPauseDispatch(t *testing.T) {
+	server := providerResponseServer(t, 407, "CouldNotRoute")
+
+	queue := &captureJobQueue{}
+	service := NewSMSService(&SMSConfig{
+		BaseURL:    server.URL,
+		RetryLimit: 3,
+		RetryDelay: time.Second,
+	}, queue)
+
+	job := &SMSJob{ID: uuid.New(), Phone: "+254700123456", Message: "test", MaxAttempts: 3}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	assert.False(t, service.dispatchPaused())
+}
+
+func TestStatusCodeMapClassify(t *testing.T) {
+	codes := DefaultStatusCodes()
+	assert.Equal(t, OutcomeSuccess, codes.Classify(100))
+	assert.Equal(t, OutcomeSuccess, codes.Classify(102))
+	assert.Equal(t, OutcomePermanent, codes.Classify(403))
+	assert.Equal(t, OutcomeRetryable, codes.Classify(405))
+	// Unknown codes default to retryable
+	assert.Equal(t, OutcomeRetryable, codes.Classify(999))
+
+	// A custom map overrides the defaults
+	custom := StatusCodeMap{999: OutcomeSuccess}
+	service := NewSMSService(&SMSConfig{StatusCodes: custom}, nil)
+	assert.Equal(t, OutcomeSuccess, service.statusCodes.Classify(999))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 90*time.Second, parseRetryAfter("90"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("garbage"))
+
+	// HTTP-date form rounds down to a positive delay
+	future := time.Now().Add(2 * time.Minute).UTC().Format(http.TimeFormat)
+	delay := parseRetryAfter(future)
+	assert.Greater(t, delay, time.Minute)
+
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	assert.Equal(t, time.Duration(0), parseRetryAfter(past))
+}
+
+func TestBuildOrderSMSMessage_TransliterationKeepsGSM7(t *testing.T) {
+	order := testOrderWithLocale("en")
+	order.Customer.Name = "Ágnes Kovács-Șteфан"
+
+	// With transliteration on, the whole message stays GSM-7
+	translit := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second, TransliterateGSM7: true}, nil)
+	message := translit.buildOrderSMSMessage(context.Background(), order)
+	encoding, _ := classifySMS(message)
+	assert.Equal(t, "GSM-7", encoding)
+	assert.Contains(t, message, "Kovacs")
+
+	// With the toggle off, originals are preserved (at UCS-2 cost)
+	plain := newTestSMSService()
+	original := plain.buildOrderSMSMessage(context.Background(), order)
+	encoding, _ = classifySMS(original)
+	assert.Equal(t, "UCS-2", encoding)
+	assert.Contains(t, original, "Kovács")
+}
+
+func TestTransliterateGSM7(t *testing.T) {
+	assert.Equal(t, "Matyas", transliterateGSM7("Mátyás"))
+	// é is part of the GSM-7 alphabet and passes through
+	assert.Equal(t, "José", transliterateGSM7("José"))
+	assert.Equal(t, "c'est ca", transliterateGSM7("c’est ça"))
+	// GSM-7 characters pass through untouched
+	assert.Equal(t, "Müller & Søn?", transliterateGSM7("Müller & Søn?"))
+	// Unmappable scripts degrade to '?' rather than forcing UCS-2
+	assert.Equal(t, "??", transliterateGSM7("Яб"))
+}
+
+func TestClassifySMS(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		encoding string
+		segments int
+	}{
+		{"empty message", "", "GSM-7", 0},
+		{"short GSM-7", "Hello John! Your order has been received.", "GSM-7", 1},
+		{"exactly one GSM-7 segment", strings.Repeat("a", 160), "GSM-7", 1},
+		{"two GSM-7 segments", strings.Repeat("a", 161), "GSM-7", 2},
+		{"three GSM-7 segments", strings.Repeat("a", 307), "GSM-7", 3},
+		{"short UCS-2", "Заказ получен", "UCS-2", 1},
+		{"exactly one UCS-2 segment", strings.Repeat("й", 70), "UCS-2", 1},
+		{"two UCS-2 segments", strings.Repeat("й", 71), "UCS-2", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, segments := classifySMS(tt.message)
+			assert.Equal(t, tt.encoding, encoding)
+			assert.Equal(t, tt.segments, segments)
+		})
+	}
+}
+
+func TestBuildOrderSMSMessage_FallsBackToDefaultLocale(t *testing.T) {
+	service := newTestSMSService()
+
+	for _, locale := range []string{"", "de", "xx"} {
+		order := testOrderWithLocale(locale)
+		message := service.buildOrderSMSMessage(context.Background(), order)
+		assert.Contains(t, message, "Hello John Doe!", "locale %q should fall back to English", locale)
+	}
+}
+
+func TestValidateSMSTemplates(t *testing.T) {
+	// The shipped templates must always render cleanly
+	require.NoError(t, ValidateSMSTemplates())
+
+	// A template with mismatched verbs is reported by name and locale
+	orderSMSTemplates["xx"] = "Hello %s, amount %d broken %v %v %v extra %s"
+	defer delete(orderSMSTemplates, "xx")
+
+	err := ValidateSMSTemplates()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "order_received[xx]")
+}
+
+// stubCostTracker accumulates cost in memory
+type stubCostTracker struct{ total float64 }
+
+func (s *stubCostTracker) AddCost(ctx context.Context, amount float64) (float64, error) {
+	s.total += amount
+	return s.total, nil
+}
+
+func (s *stubCostTracker) TodaySpend(ctx context.Context) (float64, error) {
+	return s.total, nil
+}
+
+func TestParseCost(t *testing.T) {
+	assert.Equal(t, 1.2345, parseCost("KES 1.2345"))
+	assert.Equal(t, 0.8, parseCost("0.8"))
+	assert.Equal(t, float64(0), parseCost(""))
+	assert.Equal(t, float64(0), parseCost("free"))
+}
+
+func TestProcessSMSJob_BudgetExceededPausesDispatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"SMSMessageData":{"Recipients":[{"statusCode":101,"status":"Sent","cost":"KES 0.80"}]}}`)
+	}))
+	defer server.Close()
+
+	tracker := &stubCostTracker{}
+	service := NewSMSService(&SMSConfig{
+		BaseURL:     server.URL,
+		RetryLimit:  3,
+		RetryDelay:  time.Second,
+		DailyBudget: 1.0,
+	}, &captureJobQueue{})
+	service.SetCostTracker(tracker)
+
+	sendOne := func() {
+		job := &SMSJob{ID: uuid.New(), Phone: "+254700123456", Message: "hi", MaxAttempts: 3}
+		require.NoError(t, service.processSMSJob(context.Background(), job))
+	}
+
+	// First send: 0.80 spent, still under budget
+	sendOne()
+	assert.False(t, service.dispatchPaused())
+
+	// Second send crosses the 1.0 budget and trips the circuit
+	sendOne()
+	assert.True(t, service.dispatchPaused())
+	assert.InDelta(t, 1.6, tracker.total, 0.001)
+}
+
+// stubDeliveryGuard deduplicates in memory
+type stubDeliveryGuard struct{ seen map[string]bool }
+
+func (g *stubDeliveryGuard) ClaimDeliveryReport(ctx context.Context, reportID string) (bool, error) {
+	if g.seen == nil {
+		g.seen = make(map[string]bool)
+	}
+	if g.seen[reportID] {
+		return false, nil
+	}
+	g.seen[reportID] = true
+	return true, nil
+}
+
+func (g *stubDeliveryGuard) ReleaseDeliveryReport(ctx context.Context, reportID string) error {
+	delete(g.seen, reportID)
+	return nil
+}
+
+// stubDeliveryMetrics counts delivery outcomes
+type stubDeliveryMetrics struct{ counts map[string]int }
+
+func (m *stubDeliveryMetrics) IncSMSSent(status string) {
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	m.counts[status]++
+}
+
+// stubDeliveryStore records status updates
+type stubDeliveryStore struct{ updates map[uuid.UUID]string }
+
+func (s *stubDeliveryStore) UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status string) error {
+	if s.updates == nil {
+		s.updates = make(map[uuid.UUID]string)
+	}
+	s.updates[id] = status
+	return nil
+}
+
+func TestHandleDeliveryReport_IdempotentUpdates(t *testing.T) {
+	service := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, &captureJobQueue{})
+
+	guard := &stubDeliveryGuard{}
+	metrics := &stubDeliveryMetrics{}
+	store := &stubDeliveryStore{}
+	updater := &captureOrderUpdater{}
+	service.SetDeliveryHooks(guard, metrics, store)
+	service.SetOrderStore(updater)
+
+	jobID := uuid.New()
+	orderID := uuid.New()
+	ctx := context.Background()
+
+	// First report updates everything consistently
+	require.NoError(t, service.HandleDeliveryReport(ctx, jobID, orderID, true))
+	assert.Equal(t, 1, metrics.counts["delivered"])
+	assert.Equal(t, "delivered", store.updates[jobID])
+	assert.Len(t, updater.marked, 1)
+
+	// A redelivered identical report is a no-op
+	require.NoError(t, service.HandleDeliveryReport(ctx, jobID, orderID, true))
+	assert.Equal(t, 1, metrics.counts["delivered"])
+	assert.Len(t, updater.marked, 1)
+}
+
+func TestHandleDeliveryReport_FailedDelivery(t *testing.T) {
+	service := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, &captureJobQueue{})
+
+	metrics := &stubDeliveryMetrics{}
+	store := &stubDeliveryStore{}
+	updater := &captureOrderUpdater{}
+	service.SetDeliveryHooks(&stubDeliveryGuard{}, metrics, store)
+	service.SetOrderStore(updater)
+
+	jobID := uuid.New()
+	require.NoError(t, service.HandleDeliveryReport(context.Background(), jobID, uuid.New(), false))
+
+	assert.Equal(t, 1, metrics.counts["undelivered"])
+	assert.Equal(t, "undelivered", store.updates[jobID])
+	// A failed delivery must not stamp the order as notified
+	assert.Empty(t, updater.marked)
+}
+
+// failingDeliveryStore errors a configurable number of times
+type failingDeliveryStore struct {
+	stubDeliveryStore
+	failures int
+}
+
+func (s *failingDeliveryStore) UpdateDeliveryStatus(ctx context.Context, id uuid.UUID, status string) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("database unavailable")
+	}
+	return s.stubDeliveryStore.UpdateDeliveryStatus(ctx, id, status)
+}
+
+func TestHandleDeliveryReport_FailedUpdateReleasesClaim(t *testing.T) {
+	service := NewSMSService(&SMSConfig{RetryLimit: 3, RetryDelay: time.Second}, &captureJobQueue{})
+
+	guard := &stubDeliveryGuard{}
+	store := &failingDeliveryStore{failures: 1}
+	service.SetDeliveryHooks(guard, &stubDeliveryMetrics{}, store)
+
+	jobID := uuid.New()
+	ctx := context.Background()
+
+	// The first attempt fails; the claim must be handed back
+	require.Error(t, service.HandleDeliveryReport(ctx, jobID, uuid.Nil, true))
+
+	// The provider's retry is then processed, not swallowed as a duplicate
+	require.NoError(t, service.HandleDeliveryReport(ctx, jobID, uuid.Nil, true))
+	assert.Equal(t, "delivered", store.updates[jobID])
+}
+
+func TestProcessSMSJob_TransportExhaustionRecordsFinalState(t *testing.T) {
+	// A server that's already closed: every send is a transport error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	queue := &captureJobQueue{}
+	recorder := &captureRecorder{}
+	service := NewSMSService(&SMSConfig{BaseURL: server.URL, RetryLimit: 1, RetryDelay: time.Second}, queue)
+	service.SetJobRecorder(recorder)
+
+	// The final allowed attempt fails at the transport layer
+	job := &SMSJob{ID: uuid.New(), OrderID: uuid.New(), Phone: "+254700123456", Message: "hi", MaxAttempts: 1}
+	require.NoError(t, service.processSMSJob(context.Background(), job))
+
+	assert.Equal(t, JobStatusFailed, job.Status)
+	assert.Len(t, queue.failed, 1)
+	assert.Empty(t, queue.retried)
+
+	// The most common production failure mode must reach the durable table
+	require.Len(t, recorder.records, 1)
+	assert.Equal(t, "failed", recorder.records[0].Status)
+	assert.NotEmpty(t, recorder.records[0].LastError)
+}
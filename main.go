@@ -13,6 +13,9 @@ import (
 	"backend/internal/auth"
 	"backend/internal/docs"
 	"backend/internal/jobs"
+	"backend/internal/lock"
+	"backend/internal/monitoring"
+	"backend/internal/repositories"
 	"backend/internal/services"
 	"backend/pkg/config"
 	"backend/pkg/database"
@@ -23,6 +26,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/joho/godotenv"
 )
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -32,6 +36,15 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Initialize structured logger
+	logger := monitoring.NewLogger(cfg.Environment)
+
+	// A malformed SMS template would otherwise only surface at the first
+	// real send; fail fast instead
+	if err := services.ValidateSMSTemplates(); err != nil {
+		log.Fatal("SMS template validation failed: ", err)
+	}
+
 	// Initialize database
 	log.Println("Connecting to database...")
 	if err := database.InitDatabase(cfg); err != nil {
@@ -62,37 +75,57 @@ func main() {
 		log.Println("Redis connected successfully")
 	}
 
-	// Initialize job queue
+	// Enable runtime feature-flag overrides now that Redis is available
+	cfg.Features.SetRedisClient(redisClient)
+
+	// Initialize job queue (admin job-inspection endpoints always use the
+	// Redis queue; the worker's queue backend is selectable)
 	jobQueue := jobs.NewRedisJobQueue(redisClient)
+	smsQueue, err := jobs.NewJobQueue(cfg.SMS.QueueBackend, redisClient)
+	if err != nil {
+		log.Fatal("Failed to initialize job queue:", err)
+	}
 
 	// Initialize SMS service
 	smsConfig := &services.SMSConfig{
-		Username:   cfg.SMS.Username,
-		APIKey:     cfg.SMS.APIKey,
-		Shortcode:  cfg.SMS.Shortcode,
-		BaseURL:    cfg.SMS.BaseURL,
-		IsSandbox:  cfg.SMS.IsSandbox,
-		RetryLimit: cfg.SMS.RetryLimit,
-		RetryDelay: 30 * time.Second,
-	}
-	smsService := services.NewSMSService(smsConfig, jobQueue)
-
-	// Initialize OIDC provider (if configured)
-	var oidcProvider *auth.OIDCProvider
-	if cfg.OIDC.IssuerURL != "" && cfg.OIDC.ClientID != "" {
+		Username:          cfg.SMS.Username,
+		APIKey:            cfg.SMS.APIKey,
+		Shortcode:         cfg.SMS.Shortcode,
+		BaseURL:           cfg.SMS.BaseURL,
+		IsSandbox:         cfg.SMS.IsSandbox,
+		RetryLimit:        cfg.SMS.RetryLimit,
+		RetryDelay:        30 * time.Second,
+		ShortLinkBaseURL:  cfg.SMS.ShortLinkBaseURL,
+		TransliterateGSM7: cfg.SMS.TransliterateGSM7,
+		SandboxAllowlist:  cfg.SMS.SandboxAllowlist,
+		DailyBudget:       cfg.SMS.DailyBudget,
+		MessageSuffix:     cfg.SMS.MessageSuffix,
+	}
+	smsService := services.NewSMSService(smsConfig, smsQueue)
+
+	// Initialize the authenticator: a pass-through when auth is disabled
+	// via feature flag (dev mode), otherwise the OIDC provider
+	var authenticator auth.Authenticator
+	if cfg.Features.Enabled(ctx, config.FeatureAuthDisabled) {
+		log.Println("Authentication disabled via feature flag; requests pass through unchecked")
+		authenticator = auth.NewPassthrough()
+	} else if cfg.OIDC.IssuerURL != "" && cfg.OIDC.ClientID != "" {
 		log.Println("Initializing OIDC provider...")
 		oidcConfig := &auth.OIDCConfig{
-			IssuerURL:    cfg.OIDC.IssuerURL,
-			ClientID:     cfg.OIDC.ClientID,
-			ClientSecret: cfg.OIDC.ClientSecret,
-			RedirectURL:  cfg.OIDC.RedirectURL,
-			Scopes:       cfg.OIDC.Scopes,
+			IssuerURL:       cfg.OIDC.IssuerURL,
+			ClientID:        cfg.OIDC.ClientID,
+			ClientSecret:    cfg.OIDC.ClientSecret,
+			RedirectURL:     cfg.OIDC.RedirectURL,
+			Scopes:          cfg.OIDC.Scopes,
+			AllowQueryToken: cfg.OIDC.AllowQueryToken,
+			ClockSkewLeeway: time.Duration(cfg.OIDC.ClockSkewLeewaySeconds) * time.Second,
 		}
-		var err error
-		oidcProvider, err = auth.NewOIDCProvider(oidcConfig)
+		oidcProvider, err := auth.NewOIDCProvider(oidcConfig)
 		if err != nil {
 			log.Printf("Warning: Failed to initialize OIDC provider: %v", err)
 			log.Println("Authentication will be disabled")
+		} else {
+			authenticator = oidcProvider
 		}
 	} else {
 		log.Println("OIDC configuration not provided, authentication disabled")
@@ -106,46 +139,54 @@ func main() {
 	// Initialize Gin router
 	router := gin.New()
 
-	// Add middleware
+	// Add middleware; Recovery goes first so panics anywhere later in the
+	// chain are caught
+	router.Use(middleware.Recovery(logger))
 	router.Use(middleware.Logger())
+	router.Use(middleware.CorrelationID())
 	router.Use(middleware.CORS())
-	router.Use(gin.Recovery())
+	router.Use(middleware.CamelCaseResponses())
+	if cfg.RateLimitPerMinute > 0 {
+		rateLimiter := middleware.NewRateLimiter(cfg.RateLimitPerMinute, time.Minute)
+		router.Use(rateLimiter.Middleware())
+	}
+
+	// Metrics middleware must be installed before any route is registered,
+	// or earlier routes are never counted
+	metrics := monitoring.NewMetrics()
+	router.Use(metrics.HTTPMiddleware())
+
+	// Unmatched paths get the standard JSON envelope instead of plain text
+	routes.SetupNoRoute(router)
 
 	// Setup Swagger documentation routes
 	docs.SetupSwaggerRoutes(router)
 
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		// Check database connection
-		sqlDB, err := database.GetDB().DB()
-		dbStatus := "ok"
-		if err != nil || sqlDB.Ping() != nil {
-			dbStatus = "error"
-		}
-
-		// Check Redis connection
-		redisStatus := "ok"
-		if redisClient.Ping(ctx).Err() != nil {
-			redisStatus = "error"
-		}
+	// Health and metrics endpoints (documented in the OpenAPI spec)
+	sqlDB, err := database.GetDB().DB()
+	if err != nil {
+		log.Printf("Warning: failed to get underlying sql.DB for health checks: %v", err)
+	}
+	healthChecker := monitoring.NewHealthChecker(sqlDB, redisClient, "1.0.0", logger)
+	healthChecker.SetWorkerHeartbeatSource(smsQueue.LastHeartbeat)
+	metricsGuard := middleware.MetricsGuard(cfg.Metrics.BasicAuthUser, cfg.Metrics.BasicAuthPassword, cfg.Metrics.AllowedIPs)
+	routes.SetupMonitoringRoutes(router, healthChecker, metrics, metricsGuard)
 
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "OK",
-			"message":   "Server is running",
-			"version":   "1.0.0",
-			"timestamp": time.Now().UTC(),
-			"services": gin.H{
-				"database":     dbStatus,
-				"redis":        redisStatus,
-				"sms_service":  "ok",
-				"auth_enabled": oidcProvider != nil,
-			},
+	// Profiling endpoints, admin-only and off by default
+	pprofGuard := func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "profiling requires admin authentication",
 		})
-	})
+	}
+	if authenticator != nil {
+		pprofGuard = authenticator.RequireRoles("admin")
+	}
+	routes.SetupDebugRoutes(router, cfg.PprofEnabled, pprofGuard)
 
 	// Setup routes (with conditional auth)
-	if oidcProvider != nil {
-		routes.SetupRoutes(router, database.GetDB(), oidcProvider, smsService)
+	if authenticator != nil {
+		routes.SetupRoutes(router, database.GetDB(), authenticator, smsService, jobQueue, cfg, logger, metrics)
 	} else {
 		// Setup routes without authentication for development
 		router.GET("/api/v1/*path", func(c *gin.Context) {
@@ -156,14 +197,32 @@ func main() {
 		})
 	}
 
-	// Start SMS job processor in background
+	// Start SMS job processor in background (cancellable for shutdown)
+	workerCtx, stopWorker := context.WithCancel(ctx)
+	defer stopWorker()
 	if redisClient.Ping(ctx).Err() == nil {
 		go func() {
 			log.Println("Starting SMS job processor...")
-			if err := smsService.ProcessSMSJobs(ctx); err != nil {
+			if err := smsService.ProcessSMSJobs(workerCtx); err != nil {
 				log.Printf("SMS job processor stopped: %v", err)
 			}
 		}()
+
+		// Periodically re-enqueue SMS for orders whose notification was lost
+		if cfg.SMS.ReconcileIntervalSec > 0 {
+			reconciler := services.NewSMSReconciler(
+				repositories.NewOrderRepository(database.GetDB()),
+				smsService,
+				time.Duration(cfg.SMS.ReconcileIntervalSec)*time.Second,
+				time.Duration(cfg.SMS.ReconcileOlderThanSec)*time.Second,
+			)
+			// Only one replica should reconcile at a time
+			reconciler.SetLock(lock.New(redisClient, "locks:sms_reconciler", time.Minute))
+			go func() {
+				log.Println("Starting SMS reconciler...")
+				reconciler.Run(workerCtx)
+			}()
+		}
 	}
 
 	// Setup graceful shutdown
@@ -196,6 +255,23 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// With HTTP drained, stop the worker, record the final queue depth and
+	// flush logs, bounded by a grace timeout
+	if err := services.GracefulShutdown(5*time.Second, services.ShutdownHooks{
+		StopWorker: stopWorker,
+		RecordDepth: func(shutdownCtx context.Context) error {
+			depth, err := jobQueue.PendingDepth(shutdownCtx)
+			if err != nil {
+				return err
+			}
+			metrics.SetRedisQueueDepth("pending", float64(depth))
+			return nil
+		},
+		FlushLogs: func() error { return os.Stdout.Sync() },
+	}); err != nil {
+		log.Printf("Shutdown cleanup incomplete: %v", err)
+	}
+
 	// Close database connection
 	if err := database.CloseDatabase(); err != nil {
 		log.Println("Error closing database:", err)
@@ -0,0 +1,257 @@
+// Package client provides a typed Go client for the Savannah backend API,
+// so internal services don't hand-roll HTTP calls and envelope decoding.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Client is a typed HTTP client for the API. It injects the bearer token,
+// retries transient failures and unwraps the standard response envelope.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// Option customizes a Client
+type Option func(*Client)
+
+// WithToken sets the bearer token injected into every request
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient replaces the underlying HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithRetries sets how many times transient (5xx / network) failures are
+// retried
+func WithRetries(n int) Option {
+	return func(c *Client) { c.maxRetries = n }
+}
+
+// New creates a client for the API at baseURL
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 2,
+		retryDelay: 200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response decoded from the standard envelope
+type APIError struct {
+	StatusCode int
+	Message    string
+	Detail     json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// envelope mirrors models.Response with raw data for two-step decoding
+type envelope struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// do performs a request, retrying 5xx and transport errors, and decodes
+// the envelope's data into out (when out is non-nil)
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * c.retryDelay):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		responseBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		// Retry server-side failures, but only for idempotent requests: a
+		// POST may have committed before the server errored
+		if resp.StatusCode >= http.StatusInternalServerError {
+			lastErr = decodeAPIError(resp.StatusCode, responseBody)
+			if method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete {
+				continue
+			}
+			return lastErr
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return decodeAPIError(resp.StatusCode, responseBody)
+		}
+
+		if out == nil || len(responseBody) == 0 {
+			return nil
+		}
+
+		var env envelope
+		if err := json.Unmarshal(responseBody, &env); err != nil {
+			return fmt.Errorf("failed to decode response envelope: %w", err)
+		}
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("request failed after %d attempts: %w", c.maxRetries+1, lastErr)
+}
+
+// decodeAPIError extracts the envelope message for error responses
+func decodeAPIError(status int, body []byte) error {
+	apiErr := &APIError{StatusCode: status, Message: http.StatusText(status)}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err == nil && env.Message != "" {
+		apiErr.Message = env.Message
+		apiErr.Detail = env.Error
+	}
+	return apiErr
+}
+
+// CreateCustomer creates a new customer
+func (c *Client) CreateCustomer(ctx context.Context, req *models.CreateCustomerRequest) (*models.Customer, error) {
+	var customer models.Customer
+	if err := c.do(ctx, http.MethodPost, "/api/v1/customers/", req, &customer); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetCustomer fetches a single customer by ID
+func (c *Client) GetCustomer(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+	var customer models.Customer
+	if err := c.do(ctx, http.MethodGet, "/api/v1/customers/"+id.String(), nil, &customer); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// customerListData is the data payload of the customer list endpoint
+type customerListData struct {
+	Customers  []models.Customer `json:"customers"`
+	Pagination struct {
+		Total int64 `json:"total"`
+	} `json:"pagination"`
+}
+
+// ListCustomers fetches a filtered page of customers
+func (c *Client) ListCustomers(ctx context.Context, query *models.ListCustomersQuery) ([]models.Customer, int64, error) {
+	path := "/api/v1/customers/?" + customerQueryString(query)
+
+	var data customerListData
+	if err := c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, 0, err
+	}
+	return data.Customers, data.Pagination.Total, nil
+}
+
+// DeleteCustomer soft-deletes a customer
+func (c *Client) DeleteCustomer(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/customers/"+id.String(), nil, nil)
+}
+
+// CreateOrder creates a new order
+func (c *Client) CreateOrder(ctx context.Context, req *models.CreateOrderRequest) (*models.Order, error) {
+	var order models.Order
+	if err := c.do(ctx, http.MethodPost, "/api/v1/orders/", req, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrder fetches a single order by ID
+func (c *Client) GetOrder(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	if err := c.do(ctx, http.MethodGet, "/api/v1/orders/"+id.String(), nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// orderListData is the data payload of the order list endpoint
+type orderListData struct {
+	Orders     []models.OrderListItem `json:"orders"`
+	Pagination struct {
+		Total int64 `json:"total"`
+	} `json:"pagination"`
+}
+
+// ListOrders fetches a filtered page of order list items
+func (c *Client) ListOrders(ctx context.Context, query *models.ListOrdersQuery) ([]models.OrderListItem, int64, error) {
+	path := "/api/v1/orders/?" + orderQueryString(query)
+
+	var data orderListData
+	if err := c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, 0, err
+	}
+	return data.Orders, data.Pagination.Total, nil
+}
+
+// CancelOrder cancels a pending order
+func (c *Client) CancelOrder(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	if err := c.do(ctx, http.MethodPost, "/api/v1/orders/"+id.String()+"/cancel", nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	v1 "backend/internal/api/v1"
+	"backend/internal/repositories/mocks"
+	"backend/internal/services"
+	"backend/pkg/models"
+)
+
+// newTestServer runs the real customer handler against a mock repository
+func newTestServer(t *testing.T, repo *mocks.CustomerRepository) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := v1.NewCustomerHandler(services.NewCustomerService(repo))
+
+	router := gin.New()
+	router.POST("/api/v1/customers/", handler.CreateCustomer)
+	router.GET("/api/v1/customers/", handler.ListCustomers)
+	router.GET("/api/v1/customers/:id", handler.GetCustomer)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientCreateAndGetCustomer(t *testing.T) {
+	repo := new(mocks.CustomerRepository)
+	server := newTestServer(t, repo)
+
+	created := &models.Customer{
+		ID:    uuid.New(),
+		Code:  "CUST001",
+		Name:  "John Doe",
+		Phone: "+254700123456",
+	}
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*models.Customer")).Return(created, nil)
+	repo.On("GetByID", mock.Anything, created.ID).Return(created, nil)
+
+	api := New(server.URL, WithToken("test-token"))
+	ctx := context.Background()
+
+	customer, err := api.CreateCustomer(ctx, &models.CreateCustomerRequest{
+		Code:  "CUST001",
+		Name:  "John Doe",
+		Phone: "+254700123456",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, customer.ID)
+	assert.Equal(t, "John Doe", customer.Name)
+
+	fetched, err := api.GetCustomer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.Code, fetched.Code)
+}
+
+func TestClientListCustomers(t *testing.T) {
+	repo := new(mocks.CustomerRepository)
+	server := newTestServer(t, repo)
+
+	customers := []models.Customer{
+		{ID: uuid.New(), Code: "CUST001", Name: "Customer 1"},
+		{ID: uuid.New(), Code: "CUST002", Name: "Customer 2"},
+	}
+	repo.On("Search", mock.Anything, mock.AnythingOfType("*models.ListCustomersQuery")).
+		Return(customers, int64(2), false, nil)
+
+	api := New(server.URL)
+	result, total, err := api.ListCustomers(context.Background(), &models.ListCustomersQuery{
+		PageParams: models.PageParams{Limit: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, result, 2)
+}
+
+func TestClientDecodesAPIErrors(t *testing.T) {
+	repo := new(mocks.CustomerRepository)
+	server := newTestServer(t, repo)
+
+	missing := uuid.New()
+	repo.On("GetByID", mock.Anything, missing).Return(nil, models.ErrCustomerNotFound)
+
+	api := New(server.URL)
+	_, err := api.GetCustomer(context.Background(), missing)
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestClientInjectsBearerToken(t *testing.T) {
+	var gotAuth atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth.Store(r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer server.Close()
+
+	api := New(server.URL, WithToken("secret-token"))
+	_, err := api.GetCustomer(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret-token", gotAuth.Load())
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{"name":"John Doe"}}`))
+	}))
+	defer server.Close()
+
+	api := New(server.URL, WithRetries(2))
+	api.retryDelay = time.Millisecond
+
+	customer, err := api.GetCustomer(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", customer.Name)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"success":false,"message":"Invalid request data"}`))
+	}))
+	defer server.Close()
+
+	api := New(server.URL, WithRetries(3))
+	_, err := api.GetCustomer(context.Background(), uuid.New())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+
+	"backend/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// customerQueryString encodes a customer list query into URL parameters
+func customerQueryString(query *models.ListCustomersQuery) string {
+	values := url.Values{}
+	if query == nil {
+		return ""
+	}
+	if query.Code != "" {
+		values.Set("code", query.Code)
+	}
+	if query.Name != "" {
+		values.Set("name", query.Name)
+	}
+	if query.Phone != "" {
+		values.Set("phone", query.Phone)
+	}
+	addPageParams(values, query.PageParams)
+	return values.Encode()
+}
+
+// orderQueryString encodes an order list query into URL parameters
+func orderQueryString(query *models.ListOrdersQuery) string {
+	values := url.Values{}
+	if query == nil {
+		return ""
+	}
+	if query.CustomerID != uuid.Nil {
+		values.Set("customer_id", query.CustomerID.String())
+	}
+	if query.Status != "" {
+		values.Set("status", query.Status)
+	}
+	addPageParams(values, query.PageParams)
+	return values.Encode()
+}
+
+func addPageParams(values url.Values, page models.PageParams) {
+	if page.Limit > 0 {
+		values.Set("limit", strconv.Itoa(page.Limit))
+	}
+	if page.Offset > 0 {
+		values.Set("offset", strconv.Itoa(page.Offset))
+	}
+}
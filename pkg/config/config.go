@@ -10,10 +10,55 @@ import (
 type Config struct {
 	Environment string
 	Port        string
-	Database    DatabaseConfig
-	OIDC        OIDCConfig
-	SMS         SMSConfig
-	Redis       RedisConfig
+	// RateLimitPerMinute caps requests per client IP per minute.
+	// 0 disables rate limiting.
+	RateLimitPerMinute int
+	// PprofEnabled mounts net/http/pprof under /debug/pprof (admin-guarded)
+	PprofEnabled bool
+	// StrictJSONDecoding rejects unknown request fields with 400
+	StrictJSONDecoding bool
+	Database     DatabaseConfig
+	OIDC         OIDCConfig
+	SMS          SMSConfig
+	Redis        RedisConfig
+	Orders       OrdersConfig
+	Customers    CustomersConfig
+	Metrics      MetricsConfig
+	Features     *FeatureFlags
+}
+
+// MetricsConfig controls access to the /metrics endpoint. With no
+// credentials or allowlist configured the endpoint stays open.
+type MetricsConfig struct {
+	BasicAuthUser     string
+	BasicAuthPassword string
+	AllowedIPs        []string
+}
+
+// CustomersConfig holds customer business-rule configuration
+type CustomersConfig struct {
+	// AutoGenerateCodes lets the server assign customer codes when the
+	// client omits one
+	AutoGenerateCodes bool
+	// CodePrefix prefixes generated customer codes
+	CodePrefix string
+}
+
+// OrdersConfig holds order business-rule configuration
+type OrdersConfig struct {
+	// MaxAutoAmount flags orders above this amount for manual review
+	// instead of auto-processing them. 0 disables the check.
+	MaxAutoAmount float64
+	// AutoConfirm advances new orders straight from pending to confirmed
+	AutoConfirm bool
+	// MaxPerWindow caps orders per customer within WindowSeconds.
+	// 0 disables the limit.
+	MaxPerWindow int
+	// WindowSeconds is the rate-limit window for MaxPerWindow
+	WindowSeconds int
+	// DedupWindowSeconds rejects identical submissions within this many
+	// seconds. 0 disables the guard.
+	DedupWindowSeconds int
 }
 
 // DatabaseConfig holds database configuration
@@ -24,6 +69,16 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// StatementTimeoutMS is a server-side statement_timeout applied to every
+	// connection so runaway queries are killed by Postgres itself.
+	// 0 disables it.
+	StatementTimeoutMS int
+	// Schema sets the connection search_path for deployments sharing a
+	// cluster across environments; empty uses the default (public)
+	Schema string
+	// ReadHost points analytics/export queries at a read replica; empty
+	// keeps them on the primary
+	ReadHost string
 }
 
 // OIDCConfig holds OpenID Connect configuration
@@ -33,6 +88,11 @@ type OIDCConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+	// AllowQueryToken accepts a `token` query parameter as an auth
+	// fallback for clients that can't set headers
+	AllowQueryToken bool
+	// ClockSkewLeewaySeconds tolerates clock drift in token exp/nbf checks
+	ClockSkewLeewaySeconds int
 }
 
 // SMSConfig holds SMS service configuration
@@ -43,6 +103,31 @@ type SMSConfig struct {
 	BaseURL    string
 	IsSandbox  bool
 	RetryLimit int
+	// QueueHighWater sheds order-creation load with 503s once this many SMS
+	// jobs are pending. 0 disables backpressure.
+	QueueHighWater int
+	// ReconcileIntervalSec is how often the reconciler re-enqueues SMS for
+	// un-notified orders. 0 disables the reconciler.
+	ReconcileIntervalSec int
+	// ReconcileOlderThanSec is the minimum order age before the reconciler
+	// considers its SMS lost
+	ReconcileOlderThanSec int
+	// ShortLinkBaseURL enables order view links in SMS when set
+	ShortLinkBaseURL string
+	// QueueBackend selects the job queue implementation: "redis" (default)
+	// or "memory" for single-node dev without Redis
+	QueueBackend string
+	// TransliterateGSM7 keeps SMS messages in the GSM-7 alphabet
+	TransliterateGSM7 bool
+	// SandboxAllowlist restricts sandbox-mode sends to these numbers
+	SandboxAllowlist []string
+	// MessageSuffix is appended to outgoing order SMS when the sms_suffix
+	// feature flag is on (e.g. an opt-out notice)
+	MessageSuffix string
+	// DailyBudget caps accumulated SMS cost per day; 0 disables
+	DailyBudget float64
+	// WebhookToken is the shared secret delivery callbacks must present
+	WebhookToken string
 }
 
 // RedisConfig holds Redis configuration
@@ -56,30 +141,48 @@ type RedisConfig struct {
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	return &Config{
-		Environment: getEnv("ENVIRONMENT", "development"),
-		Port:        getEnv("PORT", "8080"),
+		Environment:        getEnv("ENVIRONMENT", "development"),
+		Port:               getEnv("PORT", "8080"),
+		RateLimitPerMinute: getEnvInt("RATE_LIMIT_PER_MINUTE", 0),
+		PprofEnabled:       getEnvBool("PPROF_ENABLED", false),
+		StrictJSONDecoding: getEnvBool("STRICT_JSON_DECODING", false),
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "devuser"),
-			Password: getEnv("DB_PASSWORD", ""),
-			DBName:   getEnv("DB_NAME", "backend_dev"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:               getEnv("DB_HOST", "localhost"),
+			Port:               getEnvInt("DB_PORT", 5432),
+			User:               getEnv("DB_USER", "devuser"),
+			Password:           getEnv("DB_PASSWORD", ""),
+			DBName:             getEnv("DB_NAME", "backend_dev"),
+			SSLMode:            getEnv("DB_SSLMODE", "disable"),
+			StatementTimeoutMS: getEnvInt("DB_STATEMENT_TIMEOUT_MS", 0),
+			Schema:             getEnv("DB_SCHEMA", ""),
+			ReadHost:           getEnv("DB_READ_HOST", ""),
 		},
 		OIDC: OIDCConfig{
-			IssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
-			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
-			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
-			RedirectURL:  getEnv("OIDC_REDIRECT_URL", ""),
-			Scopes:       getEnvSlice("OIDC_SCOPES", []string{"profile", "email"}),
+			IssuerURL:              getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:               getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:           getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:            getEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:                 getEnvSlice("OIDC_SCOPES", []string{"profile", "email"}),
+			AllowQueryToken:        getEnvBool("OIDC_ALLOW_QUERY_TOKEN", false),
+			ClockSkewLeewaySeconds: getEnvInt("OIDC_CLOCK_SKEW_LEEWAY_SECONDS", 30),
 		},
 		SMS: SMSConfig{
-			Username:   getEnv("SMS_USERNAME", ""),
-			APIKey:     getEnv("SMS_API_KEY", ""),
-			Shortcode:  getEnv("SMS_SHORTCODE", ""),
-			BaseURL:    getEnv("SMS_BASE_URL", "https://api.sandbox.africastalking.com/version1"),
-			IsSandbox:  getEnvBool("SMS_IS_SANDBOX", true),
-			RetryLimit: getEnvInt("SMS_RETRY_LIMIT", 3),
+			Username:              getEnv("SMS_USERNAME", ""),
+			APIKey:                getEnv("SMS_API_KEY", ""),
+			Shortcode:             getEnv("SMS_SHORTCODE", ""),
+			BaseURL:               getEnv("SMS_BASE_URL", "https://api.sandbox.africastalking.com/version1"),
+			IsSandbox:             getEnvBool("SMS_IS_SANDBOX", true),
+			RetryLimit:            getEnvInt("SMS_RETRY_LIMIT", 3),
+			QueueHighWater:        getEnvInt("SMS_QUEUE_HIGH_WATER", 0),
+			ReconcileIntervalSec:  getEnvInt("SMS_RECONCILE_INTERVAL_SECONDS", 0),
+			ReconcileOlderThanSec: getEnvInt("SMS_RECONCILE_OLDER_THAN_SECONDS", 600),
+			ShortLinkBaseURL:      getEnv("SMS_SHORT_LINK_BASE_URL", ""),
+			QueueBackend:          getEnv("SMS_QUEUE_BACKEND", "redis"),
+			TransliterateGSM7:     getEnvBool("SMS_TRANSLITERATE_GSM7", false),
+			SandboxAllowlist:      getEnvSlice("SMS_SANDBOX_ALLOWLIST", nil),
+			MessageSuffix:         getEnv("SMS_MESSAGE_SUFFIX", ""),
+			DailyBudget:           getEnvFloat("SMS_DAILY_BUDGET", 0),
+			WebhookToken:          getEnv("SMS_WEBHOOK_TOKEN", ""),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -87,6 +190,23 @@ func LoadConfig() *Config {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvInt("REDIS_DB", 0),
 		},
+		Orders: OrdersConfig{
+			MaxAutoAmount:      getEnvFloat("ORDER_MAX_AUTO_AMOUNT", 0),
+			AutoConfirm:        getEnvBool("ORDER_AUTO_CONFIRM", false),
+			MaxPerWindow:       getEnvInt("ORDER_MAX_PER_WINDOW", 0),
+			WindowSeconds:      getEnvInt("ORDER_WINDOW_SECONDS", 3600),
+			DedupWindowSeconds: getEnvInt("ORDER_DEDUP_WINDOW_SECONDS", 0),
+		},
+		Customers: CustomersConfig{
+			AutoGenerateCodes: getEnvBool("CUSTOMER_AUTO_CODES", false),
+			CodePrefix:        getEnv("CUSTOMER_CODE_PREFIX", "CUST"),
+		},
+		Metrics: MetricsConfig{
+			BasicAuthUser:     getEnv("METRICS_BASIC_AUTH_USER", ""),
+			BasicAuthPassword: getEnv("METRICS_BASIC_AUTH_PASSWORD", ""),
+			AllowedIPs:        getEnvSlice("METRICS_ALLOWED_IPS", nil),
+		},
+		Features: LoadFeatureFlags(),
 	}
 }
 
@@ -108,6 +228,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvFloat gets an environment variable as float with a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 // getEnvBool gets an environment variable as boolean with a default value
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// FeatureFlag names an independently toggleable behavior
+type FeatureFlag string
+
+const (
+	// FeaturePhoneUniqueness enforces unique customer phone numbers
+	FeaturePhoneUniqueness FeatureFlag = "phone_uniqueness"
+	// FeatureSandboxAllowlist restricts SMS sends to an allowlist in sandbox
+	FeatureSandboxAllowlist FeatureFlag = "sandbox_allowlist"
+	// FeatureSMSSuffix appends the configured suffix to outgoing SMS
+	FeatureSMSSuffix FeatureFlag = "sms_suffix"
+	// FeatureAuthDisabled runs the API without OIDC auth (dev mode only)
+	FeatureAuthDisabled FeatureFlag = "auth_disabled"
+)
+
+// featureFlagKeyPrefix is the Redis key prefix for runtime flag overrides
+const featureFlagKeyPrefix = "feature_flags:"
+
+// FeatureFlags resolves feature toggles from environment defaults, with
+// optional runtime overrides stored in Redis taking precedence
+type FeatureFlags struct {
+	defaults map[FeatureFlag]bool
+	redis    *redis.Client
+}
+
+// LoadFeatureFlags parses the feature toggle defaults from environment
+// variables (FEATURE_PHONE_UNIQUENESS, FEATURE_SANDBOX_ALLOWLIST, ...).
+// The allowlist guard defaults on: it is a safety net, and the flag
+// exists so ops can lift it at runtime, not so it ships disabled.
+func LoadFeatureFlags() *FeatureFlags {
+	return &FeatureFlags{
+		defaults: map[FeatureFlag]bool{
+			FeaturePhoneUniqueness:  getEnvBool("FEATURE_PHONE_UNIQUENESS", false),
+			FeatureSandboxAllowlist: getEnvBool("FEATURE_SANDBOX_ALLOWLIST", true),
+			FeatureSMSSuffix:        getEnvBool("FEATURE_SMS_SUFFIX", false),
+			FeatureAuthDisabled:     getEnvBool("FEATURE_AUTH_DISABLED", false),
+		},
+	}
+}
+
+// SetRedisClient enables runtime overrides via feature_flags:<name> keys.
+// Called once Redis is connected at startup.
+func (f *FeatureFlags) SetRedisClient(client *redis.Client) {
+	f.redis = client
+}
+
+// Enabled returns the flag's value, preferring a runtime override from
+// Redis over the environment default. Unreadable or malformed overrides
+// fall back to the default.
+func (f *FeatureFlags) Enabled(ctx context.Context, flag FeatureFlag) bool {
+	if f.redis != nil {
+		if value, err := f.redis.Get(ctx, featureFlagKeyPrefix+string(flag)).Result(); err == nil {
+			if enabled, err := strconv.ParseBool(value); err == nil {
+				return enabled
+			}
+		}
+	}
+	return f.defaults[flag]
+}
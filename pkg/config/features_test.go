@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFeatureFlags_EnvParsing(t *testing.T) {
+	t.Setenv("FEATURE_PHONE_UNIQUENESS", "true")
+	t.Setenv("FEATURE_AUTH_DISABLED", "1")
+	t.Setenv("FEATURE_SMS_SUFFIX", "not-a-bool")
+
+	flags := LoadFeatureFlags()
+	ctx := context.Background()
+
+	assert.True(t, flags.Enabled(ctx, FeaturePhoneUniqueness))
+	assert.True(t, flags.Enabled(ctx, FeatureAuthDisabled))
+	// Malformed and unset values fall back to the flag's default:
+	// disabled for most, enabled for the allowlist safety net
+	assert.False(t, flags.Enabled(ctx, FeatureSMSSuffix))
+	assert.True(t, flags.Enabled(ctx, FeatureSandboxAllowlist))
+}
+
+func TestFeatureFlags_RedisOverridePrecedence(t *testing.T) {
+	t.Setenv("FEATURE_PHONE_UNIQUENESS", "false")
+	t.Setenv("FEATURE_AUTH_DISABLED", "true")
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	flags := LoadFeatureFlags()
+	flags.SetRedisClient(client)
+	ctx := context.Background()
+
+	// Runtime overrides beat env defaults in both directions
+	require.NoError(t, client.Set(ctx, "feature_flags:phone_uniqueness", "true", 0).Err())
+	require.NoError(t, client.Set(ctx, "feature_flags:auth_disabled", "false", 0).Err())
+
+	assert.True(t, flags.Enabled(ctx, FeaturePhoneUniqueness))
+	assert.False(t, flags.Enabled(ctx, FeatureAuthDisabled))
+
+	// Garbage overrides are ignored in favor of the env default
+	require.NoError(t, client.Set(ctx, "feature_flags:auth_disabled", "banana", 0).Err())
+	assert.True(t, flags.Enabled(ctx, FeatureAuthDisabled))
+
+	// Without an override the env default applies
+	assert.True(t, flags.Enabled(ctx, FeatureSandboxAllowlist))
+}
@@ -0,0 +1,71 @@
+package config
+
+// redactSecret masks a secret value while still revealing whether one was
+// configured at all
+func redactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "********"
+}
+
+// Redacted returns the effective configuration with secrets (passwords,
+// API keys, client secrets) masked, safe to expose on the admin
+// diagnostics endpoint so ops can confirm what the process actually loaded
+func (c *Config) Redacted() map[string]interface{} {
+	return map[string]interface{}{
+		"environment":           c.Environment,
+		"port":                  c.Port,
+		"rate_limit_per_minute": c.RateLimitPerMinute,
+		"database": map[string]interface{}{
+			"host":                 c.Database.Host,
+			"port":                 c.Database.Port,
+			"user":                 c.Database.User,
+			"password":             redactSecret(c.Database.Password),
+			"db_name":              c.Database.DBName,
+			"ssl_mode":             c.Database.SSLMode,
+			"statement_timeout_ms": c.Database.StatementTimeoutMS,
+		},
+		"oidc": map[string]interface{}{
+			"issuer_url":                c.OIDC.IssuerURL,
+			"client_id":                 c.OIDC.ClientID,
+			"client_secret":             redactSecret(c.OIDC.ClientSecret),
+			"redirect_url":              c.OIDC.RedirectURL,
+			"scopes":                    c.OIDC.Scopes,
+			"allow_query_token":         c.OIDC.AllowQueryToken,
+			"clock_skew_leeway_seconds": c.OIDC.ClockSkewLeewaySeconds,
+		},
+		"sms": map[string]interface{}{
+			"username":                  c.SMS.Username,
+			"api_key":                   redactSecret(c.SMS.APIKey),
+			"shortcode":                 c.SMS.Shortcode,
+			"base_url":                  c.SMS.BaseURL,
+			"is_sandbox":                c.SMS.IsSandbox,
+			"retry_limit":               c.SMS.RetryLimit,
+			"queue_high_water":          c.SMS.QueueHighWater,
+			"reconcile_interval_sec":    c.SMS.ReconcileIntervalSec,
+			"reconcile_older_than_sec":  c.SMS.ReconcileOlderThanSec,
+			"short_link_base_url":       c.SMS.ShortLinkBaseURL,
+			"queue_backend":             c.SMS.QueueBackend,
+		},
+		"redis": map[string]interface{}{
+			"host":     c.Redis.Host,
+			"port":     c.Redis.Port,
+			"password": redactSecret(c.Redis.Password),
+			"db":       c.Redis.DB,
+		},
+		"orders": map[string]interface{}{
+			"max_auto_amount": c.Orders.MaxAutoAmount,
+			"auto_confirm":    c.Orders.AutoConfirm,
+		},
+		"customers": map[string]interface{}{
+			"auto_generate_codes": c.Customers.AutoGenerateCodes,
+			"code_prefix":         c.Customers.CodePrefix,
+		},
+		"metrics": map[string]interface{}{
+			"basic_auth_user":     c.Metrics.BasicAuthUser,
+			"basic_auth_password": redactSecret(c.Metrics.BasicAuthPassword),
+			"allowed_ips":         c.Metrics.AllowedIPs,
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedacted_MasksSecretsKeepsRest(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "super-secret-db")
+	t.Setenv("SMS_API_KEY", "sk_live_abc123")
+	t.Setenv("OIDC_CLIENT_SECRET", "oidc-secret")
+	t.Setenv("DB_HOST", "db.internal")
+
+	cfg := LoadConfig()
+	redacted := cfg.Redacted()
+
+	payload, err := json.Marshal(redacted)
+	require.NoError(t, err)
+
+	// No secret value survives serialization
+	assert.NotContains(t, string(payload), "super-secret-db")
+	assert.NotContains(t, string(payload), "sk_live_abc123")
+	assert.NotContains(t, string(payload), "oidc-secret")
+
+	// Non-secret fields are present for diagnostics
+	assert.Contains(t, string(payload), "db.internal")
+
+	database := redacted["database"].(map[string]interface{})
+	assert.Equal(t, "********", database["password"])
+
+	sms := redacted["sms"].(map[string]interface{})
+	assert.Equal(t, "********", sms["api_key"])
+}
+
+func TestRedacted_EmptySecretsStayEmpty(t *testing.T) {
+	cfg := LoadConfig()
+	redacted := cfg.Redacted()
+
+	// An unset secret shows as empty so ops can see it's missing
+	database := redacted["database"].(map[string]interface{})
+	assert.Equal(t, "", database["password"])
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"backend/pkg/config"
@@ -15,8 +16,12 @@ import (
 
 var DB *gorm.DB
 
-// InitDatabase initializes the database connection
-func InitDatabase(cfg *config.Config) error {
+// ReadDB is the optional read-replica connection used by analytics and
+// export queries; nil when no replica is configured
+var ReadDB *gorm.DB
+
+// buildDSN assembles the Postgres connection string from config
+func buildDSN(cfg *config.Config) string {
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=Africa/Nairobi",
 		cfg.Database.Host,
@@ -27,6 +32,27 @@ func InitDatabase(cfg *config.Config) error {
 		cfg.Database.SSLMode,
 	)
 
+	// Connection-level options: a server-side statement timeout so runaway
+	// queries are aborted by Postgres, and an alternate schema for shared
+	// clusters
+	var options []string
+	if cfg.Database.StatementTimeoutMS > 0 {
+		options = append(options, fmt.Sprintf("-c statement_timeout=%d", cfg.Database.StatementTimeoutMS))
+	}
+	if cfg.Database.Schema != "" {
+		options = append(options, fmt.Sprintf("-c search_path=%s", cfg.Database.Schema))
+	}
+	if len(options) > 0 {
+		dsn += fmt.Sprintf(" options='%s'", strings.Join(options, " "))
+	}
+
+	return dsn
+}
+
+// InitDatabase initializes the database connection
+func InitDatabase(cfg *config.Config) error {
+	dsn := buildDSN(cfg)
+
 	// Configure GORM logger
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
@@ -54,6 +80,26 @@ func InitDatabase(cfg *config.Config) error {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
+	// Reporting load can be pointed at a read replica so exports don't
+	// compete with transactional queries
+	if cfg.Database.ReadHost != "" {
+		readCfg := *cfg
+		readCfg.Database.Host = cfg.Database.ReadHost
+		ReadDB, err = gorm.Open(postgres.Open(buildDSN(&readCfg)), gormConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect to read replica: %w", err)
+		}
+		log.Println("Connected to read replica for analytics queries")
+	}
+
+	// With an alternate schema configured, make sure it exists before
+	// migrations try to create tables inside it
+	if cfg.Database.Schema != "" {
+		if err := DB.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %q", cfg.Database.Schema)).Error; err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", cfg.Database.Schema, err)
+		}
+	}
+
 	log.Println("Successfully connected to database")
 	return nil
 }
@@ -97,6 +143,15 @@ func GetDB() *gorm.DB {
 	return DB
 }
 
+// GetReadDB returns the connection analytics/export queries should use:
+// the read replica when configured, otherwise the primary
+func GetReadDB() *gorm.DB {
+	if ReadDB != nil {
+		return ReadDB
+	}
+	return DB
+}
+
 // CloseDatabase closes the database connection
 func CloseDatabase() error {
 	if DB == nil {
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"backend/pkg/config"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Database: config.DatabaseConfig{
+			Host:     "localhost",
+			Port:     5432,
+			User:     "devuser",
+			Password: "secret",
+			DBName:   "backend_dev",
+			SSLMode:  "disable",
+		},
+	}
+}
+
+func TestBuildDSN_StatementTimeoutDisabledByDefault(t *testing.T) {
+	dsn := buildDSN(testConfig())
+	assert.NotContains(t, dsn, "statement_timeout")
+	assert.Contains(t, dsn, "host=localhost")
+	assert.Contains(t, dsn, "dbname=backend_dev")
+}
+
+func TestBuildDSN_StatementTimeoutApplied(t *testing.T) {
+	cfg := testConfig()
+	cfg.Database.StatementTimeoutMS = 30000
+
+	dsn := buildDSN(cfg)
+	assert.Contains(t, dsn, "options='-c statement_timeout=30000'")
+}
+
+func TestBuildDSN_SchemaSearchPath(t *testing.T) {
+	cfg := testConfig()
+	cfg.Database.Schema = "staging"
+
+	dsn := buildDSN(cfg)
+	assert.Contains(t, dsn, "options='-c search_path=staging'")
+
+	// Both options combine into a single options clause
+	cfg.Database.StatementTimeoutMS = 5000
+	dsn = buildDSN(cfg)
+	assert.Contains(t, dsn, "options='-c statement_timeout=5000 -c search_path=staging'")
+}
+
+func TestGetReadDB_FallsBackToPrimary(t *testing.T) {
+	origDB, origRead := DB, ReadDB
+	t.Cleanup(func() { DB, ReadDB = origDB, origRead })
+
+	primary := &gorm.DB{}
+	replica := &gorm.DB{}
+
+	DB, ReadDB = primary, nil
+	assert.Same(t, primary, GetReadDB())
+
+	ReadDB = replica
+	assert.Same(t, replica, GetReadDB())
+}
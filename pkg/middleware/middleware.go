@@ -1,10 +1,23 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
 	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"backend/internal/monitoring"
+	"backend/pkg/utils"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
 // Logger returns a gin.HandlerFunc that logs HTTP requests.
@@ -41,6 +54,87 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
+// Recovery replaces gin.Recovery: panics are logged through the
+// structured logger (carrying the request's correlation ID) with the
+// stack trace, and the client receives the standard JSON envelope instead
+// of a bare 500
+func Recovery(logger *monitoring.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.LogError(c.Request.Context(),
+					fmt.Errorf("panic: %v", recovered),
+					"Recovered from handler panic",
+					logrus.Fields{
+						"stack":  string(debug.Stack()),
+						"path":   c.Request.URL.Path,
+						"method": c.Request.Method,
+					})
+
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"message": "Internal server error",
+					"error":   gin.H{"code": "internal_error"},
+				})
+			}
+		}()
+		c.Next()
+	}
+}
+
+// StrictJSON flags every request for strict decoding (unknown request
+// fields are rejected) when enabled in config
+func StrictJSON(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if enabled {
+			c.Set(utils.StrictJSONKey, true)
+		}
+		c.Next()
+	}
+}
+
+// RequireJSON rejects write requests with a non-JSON Content-Type using a
+// clear 415, instead of letting JSON binding fail with a vague error.
+// Requests without a Content-Type (e.g. bodyless POSTs like cancel) pass
+// through to the binder.
+func RequireJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			contentType := c.ContentType()
+			if contentType != "" && contentType != "application/json" {
+				c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+					"success": false,
+					"message": "Content-Type must be application/json",
+					"error":   gin.H{"code": "unsupported_media_type"},
+				})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// CorrelationID assigns each request a correlation ID (propagating the
+// caller's X-Correlation-ID when present) plus a fresh request ID, storing
+// both in the request context for the structured logger and echoing the
+// correlation ID back to the client
+func CorrelationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := c.GetHeader("X-Correlation-ID")
+		if correlationID == "" {
+			correlationID = uuid.New().String()
+		}
+
+		ctx := utils.WithCorrelationID(c.Request.Context(), correlationID)
+		ctx = utils.WithRequestID(ctx, uuid.New().String())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Header("X-Correlation-ID", correlationID)
+		c.Next()
+	}
+}
+
 // Auth middleware for authentication (placeholder implementation)
 func Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -50,11 +144,205 @@ func Auth() gin.HandlerFunc {
 	}
 }
 
-// RateLimit middleware for rate limiting (placeholder implementation)
-func RateLimit() gin.HandlerFunc {
+// QueueBackpressure sheds write load when the backing job queue is
+// saturated: once the pending depth reaches highWater, requests are
+// rejected with 503 and a Retry-After hint instead of accepting orders we
+// can't notify about. A highWater of 0 disables the check, and stats
+// failures fail open so Redis trouble doesn't block order creation.
+func QueueBackpressure(highWater int64, retryAfter time.Duration, pendingDepth func(ctx context.Context) (int64, error)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// TODO: Implement rate limiting logic
-		// For now, this is a placeholder that allows all requests
+		if highWater <= 0 {
+			c.Next()
+			return
+		}
+
+		depth, err := pendingDepth(c.Request.Context())
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		if depth >= highWater {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"message": "Service is shedding load, please retry later",
+				"error":   gin.H{"code": "queue.saturated"},
+			})
+			return
+		}
+
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// RateLimiter is a fixed-window in-memory rate limiter keyed by client IP
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	counters map[string]*rateWindow
+}
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// NewRateLimiter allows limit requests per client per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:    limit,
+		window:   window,
+		counters: make(map[string]*rateWindow),
+	}
+}
+
+// take consumes one request slot for the key, reporting the remaining
+// budget and when the window resets
+func (rl *RateLimiter) take(key string) (remaining int, resetAt time.Time, allowed bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+
+	// Opportunistically drop expired windows so the map doesn't grow with
+	// every client IP ever seen
+	if len(rl.counters) > 0 && len(rl.counters)%1024 == 0 {
+		for k, w := range rl.counters {
+			if now.After(w.resetAt) {
+				delete(rl.counters, k)
+			}
+		}
+	}
+
+	window, ok := rl.counters[key]
+	if !ok || now.After(window.resetAt) {
+		window = &rateWindow{resetAt: now.Add(rl.window)}
+		rl.counters[key] = window
+	}
+
+	if window.count >= rl.limit {
+		return 0, window.resetAt, false
+	}
+
+	window.count++
+	return rl.limit - window.count, window.resetAt, true
+}
+
+// Middleware enforces the limit and emits X-RateLimit-* headers on every
+// response (not just 429s) so clients can self-throttle
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		remaining, resetAt, allowed := rl.take(c.ClientIP())
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rl.limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(resetAt).Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"message": "Rate limit exceeded",
+				"error":   gin.H{"code": "rate_limited"},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// camelCaseHeader selects the camelCase response transform per request
+const camelCaseHeader = "X-Response-Case"
+
+// bufferingWriter captures the response body so it can be transformed
+// before reaching the client
+type bufferingWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bufferingWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *bufferingWriter) WriteString(s string) (int, error) {
+	return w.buf.WriteString(s)
+}
+
+// Flush hands buffered bytes straight to the client so streaming
+// responses (SSE) keep working; flushed chunks skip the key transform
+func (w *bufferingWriter) Flush() {
+	if w.buf.Len() > 0 {
+		w.ResponseWriter.Write(w.buf.Bytes()) //nolint:errcheck
+		w.buf.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// CamelCaseResponses re-keys JSON responses to camelCase when the client
+// sends "X-Response-Case: camel". The models keep their snake_case tags;
+// requests without the header pass through untouched.
+func CamelCaseResponses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.EqualFold(c.GetHeader(camelCaseHeader), "camel") {
+			c.Next()
+			return
+		}
+
+		writer := &bufferingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		body := writer.buf.Bytes()
+		if strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			if converted, err := utils.ConvertKeysToCamel(body); err == nil {
+				body = converted
+			}
+		}
+		writer.ResponseWriter.Write(body) //nolint:errcheck
+	}
+}
+
+// MetricsGuard optionally protects the metrics endpoint with basic auth
+// and/or a source-IP allowlist. With neither configured it passes every
+// request through, keeping the endpoint open for backward compatibility.
+func MetricsGuard(username, password string, allowedIPs []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(allowedIPs))
+	for _, ip := range allowedIPs {
+		if ip != "" {
+			allowed[ip] = true
+		}
+	}
+	basicAuthConfigured := username != "" && password != ""
+
+	return func(c *gin.Context) {
+		if !basicAuthConfigured && len(allowed) == 0 {
+			c.Next()
+			return
+		}
+
+		if allowed[c.ClientIP()] {
+			c.Next()
+			return
+		}
+
+		if basicAuthConfigured {
+			user, pass, ok := c.Request.BasicAuth()
+			if ok && subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1 {
+				c.Next()
+				return
+			}
+			c.Header("WWW-Authenticate", `Basic realm="metrics"`)
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Metrics access denied",
+			"error":   gin.H{"code": "unauthorized"},
+		})
+	}
+}
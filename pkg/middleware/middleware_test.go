@@ -0,0 +1,323 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/monitoring"
+	"backend/pkg/models"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func backpressureRouter(highWater int64, depth func(ctx context.Context) (int64, error)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/orders", QueueBackpressure(highWater, 30*time.Second, depth), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func TestQueueBackpressure_BelowHighWaterPasses(t *testing.T) {
+	router := backpressureRouter(10, func(ctx context.Context) (int64, error) { return 5, nil })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestQueueBackpressure_AtHighWaterShedsLoad(t *testing.T) {
+	router := backpressureRouter(10, func(ctx context.Context) (int64, error) { return 10, nil })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "queue.saturated")
+}
+
+func TestQueueBackpressure_DisabledByDefault(t *testing.T) {
+	router := backpressureRouter(0, func(ctx context.Context) (int64, error) {
+		t.Fatal("depth should not be consulted when disabled")
+		return 0, nil
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func rateLimitedRouter(limit int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRateLimiter(limit, time.Minute).Middleware())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestRateLimiter_HeadersOnAllowedRequests(t *testing.T) {
+	router := rateLimitedRouter(3)
+
+	// Remaining decrements on each sequential allowed request
+	for i, wantRemaining := range []string{"2", "1", "0"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, "request %d", i)
+		assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, wantRemaining, w.Header().Get("X-RateLimit-Remaining"))
+		assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+	}
+
+	// The next request is rejected but still carries the headers
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "rate_limited")
+}
+
+func TestRateLimiter_ConcurrentRequestsNeverExceedLimit(t *testing.T) {
+	const limit = 10
+	router := rateLimitedRouter(limit)
+
+	var allowed int64
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code == http.StatusOK {
+				atomic.AddInt64(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(limit), allowed)
+}
+
+func TestCamelCaseResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CamelCaseResponses())
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"customer_id": "abc",
+			"created_at":  "2026-01-01T00:00:00Z",
+			"items":       []gin.H{{"unit_price": 10}},
+		})
+	})
+
+	// Without the header the snake_case keys pass through untouched
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, w.Body.String(), `"customer_id"`)
+
+	// With the header every key (including nested ones) is camelCase
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Response-Case", "camel")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"customerId"`)
+	assert.Contains(t, w.Body.String(), `"createdAt"`)
+	assert.Contains(t, w.Body.String(), `"unitPrice"`)
+	assert.NotContains(t, w.Body.String(), `"customer_id"`)
+}
+
+func metricsGuardRouter(user, pass string, allowedIPs []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", MetricsGuard(user, pass, allowedIPs), func(c *gin.Context) {
+		c.String(http.StatusOK, "metrics")
+	})
+	return router
+}
+
+func TestMetricsGuard_OpenByDefault(t *testing.T) {
+	router := metricsGuardRouter("", "", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMetricsGuard_BasicAuth(t *testing.T) {
+	router := metricsGuardRouter("prom", "scrape-secret", nil)
+
+	// No credentials
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+
+	// Wrong credentials
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.SetBasicAuth("prom", "wrong")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Correct credentials
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.SetBasicAuth("prom", "scrape-secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMetricsGuard_IPAllowlist(t *testing.T) {
+	router := metricsGuardRouter("", "", []string{"10.0.0.5"})
+
+	// httptest requests come from 192.0.2.1, not the allowlist
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	allowed := metricsGuardRouter("", "", []string{"192.0.2.1"})
+	w = httptest.NewRecorder()
+	allowed.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestQueueBackpressure_FailsOpenOnStatsError(t *testing.T) {
+	router := backpressureRouter(10, func(ctx context.Context) (int64, error) {
+		return 0, errors.New("redis unavailable")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestCorrelationID_PropagatesAndEchoes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CorrelationID())
+
+	var seenCorrelation, seenRequest string
+	router.GET("/", func(c *gin.Context) {
+		seenCorrelation = utils.CorrelationIDFromContext(c.Request.Context())
+		seenRequest = utils.RequestIDFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	// A caller-supplied correlation ID is propagated and echoed back
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Correlation-ID", "upstream-id")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "upstream-id", seenCorrelation)
+	assert.Equal(t, "upstream-id", w.Header().Get("X-Correlation-ID"))
+	assert.NotEmpty(t, seenRequest)
+
+	// Without one, the server assigns a fresh correlation ID
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEmpty(t, w.Header().Get("X-Correlation-ID"))
+}
+
+func TestRequireJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequireJSON())
+	router.POST("/write", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	router.GET("/read", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Form-encoded writes are rejected with 415
+	req := httptest.NewRequest(http.MethodPost, "/write", strings.NewReader("a=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Contains(t, w.Body.String(), "unsupported_media_type")
+
+	// JSON writes pass
+	req = httptest.NewRequest(http.MethodPost, "/write", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Bodyless writes without a Content-Type pass through
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/write", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Reads are never affected
+	req = httptest.NewRequest(http.MethodGet, "/read", nil)
+	req.Header.Set("Content-Type", "text/plain")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRecovery_EnvelopeAndStructuredLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := monitoring.NewLogger("test")
+	var logBuf bytes.Buffer
+	logger.SetOutput(&logBuf)
+
+	router := gin.New()
+	router.Use(CorrelationID())
+	router.Use(Recovery(logger))
+	router.GET("/boom", func(c *gin.Context) {
+		panic("something exploded")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+	req.Header.Set("X-Correlation-ID", "corr-panic-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// The client gets the standard envelope, not an empty 500
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"success":false`)
+	assert.Contains(t, w.Body.String(), `"code":"internal_error"`)
+	assert.NotContains(t, w.Body.String(), "something exploded")
+
+	// The panic is logged with stack and correlation ID
+	logged := logBuf.String()
+	assert.Contains(t, logged, "something exploded")
+	assert.Contains(t, logged, "corr-panic-1")
+	assert.Contains(t, logged, "middleware_test.go")
+}
+
+func TestCamelCaseResponses_PreservesMoneyFormatting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CamelCaseResponses())
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"order_amount": models.Money(100)})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Response-Case", "camel")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// The fixed two-decimal rendering survives the key transform
+	assert.Contains(t, w.Body.String(), `"orderAmount":100.00`)
+}
@@ -37,6 +37,48 @@ func getAllMigrations() []MigrationItem {
 			Up:          addAuditTriggers,
 			Down:        dropAuditTriggers,
 		},
+		{
+			Version:     "006_add_customer_locale",
+			Description: "Add locale column to customers for localized SMS",
+			Up:          addCustomerLocale,
+			Down:        dropCustomerLocale,
+		},
+		{
+			Version:     "007_create_order_short_links",
+			Description: "Create short link codes for order SMS links",
+			Up:          createOrderShortLinks,
+			Down:        dropOrderShortLinks,
+		},
+		{
+			Version:     "008_create_sms_jobs",
+			Description: "Create durable record of final SMS job states",
+			Up:          createSMSJobs,
+			Down:        dropSMSJobs,
+		},
+		{
+			Version:     "009_add_tenant_id",
+			Description: "Add optional tenant_id columns for per-tenant isolation",
+			Up:          addTenantID,
+			Down:        dropTenantID,
+		},
+		{
+			Version:     "010_create_order_items",
+			Description: "Create order_items table for multi-item orders",
+			Up:          createOrderItems,
+			Down:        dropOrderItems,
+		},
+		{
+			Version:     "011_create_order_events",
+			Description: "Create append-only order lifecycle event log",
+			Up:          createOrderEvents,
+			Down:        dropOrderEvents,
+		},
+		{
+			Version:     "012_case_insensitive_customer_code",
+			Description: "Make the customer code unique index case-insensitive",
+			Up:          caseInsensitiveCustomerCode,
+			Down:        dropCaseInsensitiveCustomerCode,
+		},
 	}
 }
 
@@ -265,3 +307,156 @@ func dropAuditTriggers(db *gorm.DB) error {
 
 	return nil
 }
+
+// Migration 006: Add customer locale column
+func addCustomerLocale(db *gorm.DB) error {
+	return db.Exec(`
+		ALTER TABLE customers
+		ADD COLUMN IF NOT EXISTS locale VARCHAR(10) DEFAULT 'en'
+	`).Error
+}
+
+func dropCustomerLocale(db *gorm.DB) error {
+	return db.Exec("ALTER TABLE customers DROP COLUMN IF EXISTS locale").Error
+}
+
+// Migration 007: Create order short links table
+func createOrderShortLinks(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS order_short_links (
+			code VARCHAR(16) PRIMARY KEY,
+			order_id UUID NOT NULL UNIQUE,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+		)
+	`).Error
+}
+
+func dropOrderShortLinks(db *gorm.DB) error {
+	return db.Exec("DROP TABLE IF EXISTS order_short_links CASCADE").Error
+}
+
+// Migration 008: Create sms_jobs table
+func createSMSJobs(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS sms_jobs (
+			id UUID PRIMARY KEY,
+			order_id UUID,
+			customer_id UUID,
+			phone VARCHAR(20),
+			message TEXT,
+			status VARCHAR(20),
+			attempts INTEGER DEFAULT 0,
+			last_error TEXT,
+			created_at TIMESTAMPTZ,
+			completed_at TIMESTAMPTZ
+		);
+		CREATE INDEX IF NOT EXISTS idx_sms_jobs_order_id ON sms_jobs(order_id);
+		CREATE INDEX IF NOT EXISTS idx_sms_jobs_status ON sms_jobs(status)
+	`).Error
+}
+
+func dropSMSJobs(db *gorm.DB) error {
+	return db.Exec("DROP TABLE IF EXISTS sms_jobs CASCADE").Error
+}
+
+// Migration 009: Add tenant_id columns
+func addTenantID(db *gorm.DB) error {
+	queries := []string{
+		"ALTER TABLE customers ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64)",
+		"ALTER TABLE orders ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64)",
+		"CREATE INDEX IF NOT EXISTS idx_customers_tenant ON customers(tenant_id)",
+		"CREATE INDEX IF NOT EXISTS idx_orders_tenant ON orders(tenant_id)",
+	}
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropTenantID(db *gorm.DB) error {
+	queries := []string{
+		"DROP INDEX IF EXISTS idx_customers_tenant",
+		"DROP INDEX IF EXISTS idx_orders_tenant",
+		"ALTER TABLE customers DROP COLUMN IF EXISTS tenant_id",
+		"ALTER TABLE orders DROP COLUMN IF EXISTS tenant_id",
+	}
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migration 010: Create order_items table
+func createOrderItems(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS order_items (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			order_id UUID NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			quantity INTEGER NOT NULL,
+			unit_price NUMERIC(12,2) NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id)
+	`).Error
+}
+
+func dropOrderItems(db *gorm.DB) error {
+	return db.Exec("DROP TABLE IF EXISTS order_items CASCADE").Error
+}
+
+// Migration 011: Create order_events table
+func createOrderEvents(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS order_events (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			order_id UUID NOT NULL,
+			type VARCHAR(32) NOT NULL,
+			actor VARCHAR(100),
+			detail TEXT,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_order_events_order_id ON order_events(order_id)
+	`).Error
+}
+
+func dropOrderEvents(db *gorm.DB) error {
+	return db.Exec("DROP TABLE IF EXISTS order_events CASCADE").Error
+}
+
+// Migration 012: Case-insensitive unique customer codes
+func caseInsensitiveCustomerCode(db *gorm.DB) error {
+	queries := []string{
+		// Normalize any existing mixed-case codes before tightening the index
+		"UPDATE customers SET code = upper(code)",
+		"DROP INDEX IF EXISTS idx_customers_code",
+		"ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_code_key",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_code_upper ON customers (upper(code))",
+	}
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropCaseInsensitiveCustomerCode(db *gorm.DB) error {
+	queries := []string{
+		"DROP INDEX IF EXISTS idx_customers_code_upper",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_code ON customers(code)",
+	}
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
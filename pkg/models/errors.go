@@ -0,0 +1,34 @@
+package models
+
+import "errors"
+
+// Sentinel errors returned by repositories and services, so callers can
+// branch on error identity instead of matching gorm internals or strings
+var (
+	// ErrCustomerNotFound is returned when a customer lookup matches no
+	// active row
+	ErrCustomerNotFound = errors.New("customer not found")
+
+	// ErrOrderNotFound is returned when an order lookup matches no active row
+	ErrOrderNotFound = errors.New("order not found")
+
+	// ErrVersionConflict signals an optimistic-concurrency failure: the
+	// row's version changed between read and write
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrValidation wraps business-rule validation failures from the
+	// service layer
+	ErrValidation = errors.New("validation failed")
+
+	// ErrImmutableField signals an attempt to change a field that is fixed
+	// after creation (e.g. a customer's code)
+	ErrImmutableField = errors.New("field is immutable")
+
+	// ErrOrderRateLimited signals the customer created too many orders
+	// within the configured window
+	ErrOrderRateLimited = errors.New("order rate limit exceeded")
+
+	// ErrPossibleDuplicate signals an identical order submission within
+	// the dedup window (likely a double-click)
+	ErrPossibleDuplicate = errors.New("possible duplicate order")
+)
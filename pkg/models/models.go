@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +23,8 @@ type Customer struct {
 	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
 	Phone     string    `json:"phone" gorm:"type:varchar(20);index"`
 	Email     string    `json:"email" gorm:"type:varchar(255)"`
+	Locale    string    `json:"locale" gorm:"type:varchar(10);default:'en'"`
+	TenantID  string    `json:"tenant_id,omitempty" gorm:"type:varchar(64);index"`
 	Version   int       `json:"version" gorm:"default:1"`
 	IsActive  bool      `json:"is_active" gorm:"default:true;index"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
@@ -30,22 +34,177 @@ type Customer struct {
 	Orders []Order `json:"orders,omitempty" gorm:"foreignKey:CustomerID"`
 }
 
+// MarshalJSON renders all customer timestamps in UTC, so responses are
+// consistent regardless of the DB session timezone (Africa/Nairobi in the
+// DSN)
+func (c Customer) MarshalJSON() ([]byte, error) {
+	type plain Customer
+	normalized := plain(c)
+	normalized.CreatedAt = normalized.CreatedAt.UTC()
+	normalized.UpdatedAt = normalized.UpdatedAt.UTC()
+	return json.Marshal(normalized)
+}
+
+// Money is a currency amount that always serializes to JSON with exactly
+// two decimals, avoiding float artifacts like 99.99000000000001
+type Money float64
+
+// MarshalJSON renders the amount as a number rounded to two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(m), 'f', 2, 64)), nil
+}
+
+// UnmarshalJSON accepts any JSON number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return err
+	}
+	*m = Money(value)
+	return nil
+}
+
 // Order represents an order in the system
 type Order struct {
 	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	CustomerID uuid.UUID  `json:"customer_id" gorm:"type:uuid;not null;index"`
 	Item       string     `json:"item" gorm:"type:varchar(255);not null"`
-	Amount     float64    `json:"amount" gorm:"type:numeric(12,2);not null"`
-	OrderedAt  time.Time  `json:"ordered_at" gorm:"index"`
-	Status     string     `json:"status" gorm:"type:varchar(20);default:'pending';index"`
-	SMSSentAt  *time.Time `json:"sms_sent_at,omitempty"`
-	Version    int        `json:"version" gorm:"default:1"`
-	IsActive   bool       `json:"is_active" gorm:"default:true;index"`
-	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	
+	Amount     Money      `json:"amount" gorm:"type:numeric(12,2);not null"`
+	TenantID   string     `json:"tenant_id,omitempty" gorm:"type:varchar(64);index"`
+	OrderedAt  time.Time   `json:"ordered_at" gorm:"index"`
+	Status     OrderStatus `json:"status" gorm:"type:varchar(20);default:'pending';index"`
+	SMSSentAt  *time.Time  `json:"sms_sent_at,omitempty"`
+	Version    int         `json:"version" gorm:"default:1"`
+	IsActive   bool        `json:"is_active" gorm:"default:true;index"`
+	CreatedAt  time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+
 	// Relations
-	Customer Customer `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	Customer Customer    `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	Items    []OrderItem `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+}
+
+// MarshalJSON renders all order timestamps in UTC; see Customer.MarshalJSON
+func (o Order) MarshalJSON() ([]byte, error) {
+	type plain Order
+	normalized := plain(o)
+	normalized.OrderedAt = normalized.OrderedAt.UTC()
+	normalized.CreatedAt = normalized.CreatedAt.UTC()
+	normalized.UpdatedAt = normalized.UpdatedAt.UTC()
+	if normalized.SMSSentAt != nil {
+		utc := normalized.SMSSentAt.UTC()
+		normalized.SMSSentAt = &utc
+	}
+	return json.Marshal(normalized)
+}
+
+// OrderListItem is the lightweight list projection of an order: the
+// customer appears as just id + name, so list responses stay small and
+// customer PII (phone, email) never leaks into order lists
+type OrderListItem struct {
+	ID           uuid.UUID   `json:"id"`
+	CustomerID   uuid.UUID   `json:"customer_id"`
+	CustomerName string      `json:"customer_name"`
+	Item         string      `json:"item"`
+	Amount       Money       `json:"amount"`
+	OrderedAt    time.Time   `json:"ordered_at"`
+	Status       OrderStatus `json:"status"`
+	SMSSentAt    *time.Time  `json:"sms_sent_at,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// MarshalJSON renders list-item timestamps in UTC; see Customer.MarshalJSON
+func (o OrderListItem) MarshalJSON() ([]byte, error) {
+	type plain OrderListItem
+	normalized := plain(o)
+	normalized.OrderedAt = normalized.OrderedAt.UTC()
+	normalized.CreatedAt = normalized.CreatedAt.UTC()
+	if normalized.SMSSentAt != nil {
+		utc := normalized.SMSSentAt.UTC()
+		normalized.SMSSentAt = &utc
+	}
+	return json.Marshal(normalized)
+}
+
+// OrderItem is a single line item belonging to an order
+type OrderItem struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrderID   uuid.UUID `json:"order_id" gorm:"type:uuid;not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	Quantity  int       `json:"quantity" gorm:"not null"`
+	UnitPrice Money     `json:"unit_price" gorm:"type:numeric(12,2);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Total returns the line total for this item
+func (i OrderItem) Total() Money {
+	return Money(float64(i.UnitPrice) * float64(i.Quantity))
+}
+
+// OrderItemsTotal sums the line totals of all items
+func OrderItemsTotal(items []OrderItem) Money {
+	var total Money
+	for _, item := range items {
+		total += item.Total()
+	}
+	return total
+}
+
+// Order lifecycle event types
+const (
+	OrderEventCreated   = "order_created"
+	OrderEventConfirmed = "order_confirmed"
+	OrderEventCancelled = "order_cancelled"
+	OrderEventSMSSent   = "sms_sent"
+	OrderEventSMSFailed = "sms_failed"
+	OrderEventCorrected = "order_corrected"
+)
+
+// OrderEvent is one entry in the append-only order lifecycle log
+type OrderEvent struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrderID   uuid.UUID `json:"order_id" gorm:"type:uuid;not null;index"`
+	Type      string    `json:"type" gorm:"type:varchar(32);not null"`
+	Actor     string    `json:"actor" gorm:"type:varchar(100)"`
+	Detail    string    `json:"detail,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the default pluralization
+func (OrderEvent) TableName() string {
+	return "order_events"
+}
+
+// OrderShortLink maps a short code (used in SMS links) to an order
+type OrderShortLink struct {
+	Code      string    `json:"code" gorm:"type:varchar(16);primaryKey"`
+	OrderID   uuid.UUID `json:"order_id" gorm:"type:uuid;not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the default pluralization
+func (OrderShortLink) TableName() string {
+	return "order_short_links"
+}
+
+// SMSJobRecord is the durable Postgres copy of an SMS job's final state,
+// surviving Redis key expiry for long-term audit
+type SMSJobRecord struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	OrderID     uuid.UUID  `json:"order_id" gorm:"type:uuid;index"`
+	CustomerID  uuid.UUID  `json:"customer_id" gorm:"type:uuid;index"`
+	Phone       string     `json:"phone" gorm:"type:varchar(20)"`
+	Message     string     `json:"message" gorm:"type:text"`
+	Status      string     `json:"status" gorm:"type:varchar(20);index"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName overrides the default pluralization
+func (SMSJobRecord) TableName() string {
+	return "sms_jobs"
 }
 
 // History tables for audit trail
@@ -77,36 +236,146 @@ type OrderHistory struct {
 
 // Request/Response models
 type CreateCustomerRequest struct {
-	Code  string `json:"code" binding:"required,min=2,max=32"`
-	Name  string `json:"name" binding:"required,min=2,max=255"`
-	Phone string `json:"phone" binding:"required,min=10,max=20"`
-	Email string `json:"email" binding:"omitempty,email"`
+	// Code may be omitted when server-assigned codes are enabled
+	Code   string `json:"code" binding:"omitempty,min=2,max=32"`
+	Name   string `json:"name" binding:"required,min=2,max=255" normalize:"name"`
+	Phone  string `json:"phone" binding:"omitempty,min=10,max=20"`
+	Email  string `json:"email" binding:"omitempty,email"`
+	Locale string `json:"locale" binding:"omitempty,min=2,max=10"`
 }
 
 type UpdateCustomerRequest struct {
-	Name  string `json:"name" binding:"omitempty,min=2,max=255"`
-	Phone string `json:"phone" binding:"omitempty,min=10,max=20"`
-	Email string `json:"email" binding:"omitempty,email"`
+	// Code is accepted in the payload only so attempts to change it can be
+	// rejected explicitly rather than silently ignored
+	Code   string `json:"code" binding:"omitempty"`
+	Name   string `json:"name" binding:"omitempty,min=2,max=255" normalize:"name"`
+	Phone  string `json:"phone" binding:"omitempty,min=10,max=20"`
+	Email  string `json:"email" binding:"omitempty,email"`
+	Locale string `json:"locale" binding:"omitempty,min=2,max=10"`
+}
+
+// ExtraJSONFields declares the alias keys UnmarshalJSON accepts beyond
+// the struct tags, so strict decoding doesn't reject them
+func (CreateCustomerRequest) ExtraJSONFields() []string {
+	return []string{"phone_number"}
+}
+
+// ExtraJSONFields mirrors CreateCustomerRequest.ExtraJSONFields
+func (UpdateCustomerRequest) ExtraJSONFields() []string {
+	return []string{"phone_number"}
+}
+
+// UnmarshalJSON accepts the previously documented `phone_number` field as
+// an alias for the canonical `phone`, so clients built against the older
+// spec keep working. An explicit `phone` always wins.
+func (r *CreateCustomerRequest) UnmarshalJSON(data []byte) error {
+	type plain CreateCustomerRequest
+	aux := struct {
+		*plain
+		PhoneNumber string `json:"phone_number"`
+	}{plain: (*plain)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if r.Phone == "" && aux.PhoneNumber != "" {
+		r.Phone = aux.PhoneNumber
+	}
+	return nil
+}
+
+// UnmarshalJSON accepts `phone_number` as an alias for `phone`; see
+// CreateCustomerRequest.UnmarshalJSON
+func (r *UpdateCustomerRequest) UnmarshalJSON(data []byte) error {
+	type plain UpdateCustomerRequest
+	aux := struct {
+		*plain
+		PhoneNumber string `json:"phone_number"`
+	}{plain: (*plain)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if r.Phone == "" && aux.PhoneNumber != "" {
+		r.Phone = aux.PhoneNumber
+	}
+	return nil
+}
+
+// PatchCustomerRequest uses pointer fields so omitted keys (nil) are
+// distinguishable from explicit empty values, allowing fields to be cleared
+type PatchCustomerRequest struct {
+	Code   *string `json:"code" binding:"omitempty"`
+	Name   *string `json:"name" binding:"omitempty" normalize:"name"`
+	Phone  *string `json:"phone" binding:"omitempty"`
+	Email  *string `json:"email" binding:"omitempty"`
+	Locale *string `json:"locale" binding:"omitempty"`
+}
+
+// OrderItemRequest is a single line item in a create-order payload
+type OrderItemRequest struct {
+	Name      string  `json:"name" binding:"required,min=2,max=255" normalize:"name"`
+	Quantity  int     `json:"quantity" binding:"required,gt=0"`
+	UnitPrice float64 `json:"unit_price" binding:"required,gt=0"`
 }
 
+// CreateOrderRequest accepts either the legacy single item+amount shape or
+// a list of line items (in which case the amount is derived from the sum)
 type CreateOrderRequest struct {
-	CustomerID uuid.UUID  `json:"customer_id" binding:"required"`
-	Item       string     `json:"item" binding:"required,min=2,max=255"`
-	Amount     float64    `json:"amount" binding:"required,gt=0"`
-	OrderedAt  *time.Time `json:"ordered_at,omitempty"`
+	CustomerID uuid.UUID          `json:"customer_id" binding:"required"`
+	Item       string             `json:"item" binding:"omitempty,min=2,max=255"`
+	Amount     float64            `json:"amount" binding:"omitempty,gt=0"`
+	Items      []OrderItemRequest `json:"items" binding:"omitempty,dive"`
+	OrderedAt  *time.Time         `json:"ordered_at,omitempty"`
+	// Force bypasses the short-window duplicate-submission guard
+	Force bool `json:"force,omitempty"`
+}
+
+// PatchOrderRequest corrects a pending order's item or amount; pointer
+// fields distinguish omitted keys from explicit values
+type PatchOrderRequest struct {
+	Item   *string  `json:"item" binding:"omitempty,min=2,max=255"`
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// PageParams is the shared pagination block embedded in list query structs
+type PageParams struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+	// Count controls whether the expensive COUNT(*) runs; clients that
+	// only page forward can pass count=false and rely on has_more
+	Count bool `form:"count,default=true"`
+}
+
+const (
+	// DefaultPageLimit applies when the client doesn't specify a limit
+	DefaultPageLimit = 20
+	// MaxPageLimit caps how many rows a single page may return
+	MaxPageLimit = 100
+)
+
+// Normalize applies the default page size and clamps out-of-range values
+func (p *PageParams) Normalize() {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
 }
 
 type ListCustomersQuery struct {
-	Code   string `form:"code"`
-	Name   string `form:"name"`
-	Phone  string `form:"phone"`
-	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
-	Offset int    `form:"offset" binding:"omitempty,min=0"`
+	Code  string `form:"code"`
+	Name  string `form:"name"`
+	Phone string `form:"phone"`
+	PageParams
 }
 
 type ListOrdersQuery struct {
 	CustomerID uuid.UUID `form:"customer_id"`
 	Status     string    `form:"status"`
-	Limit      int       `form:"limit" binding:"omitempty,min=1,max=100"`
-	Offset     int       `form:"offset" binding:"omitempty,min=0"`
+	PageParams
 }
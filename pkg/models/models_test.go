@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCustomerRequest_PhoneNumberAlias(t *testing.T) {
+	// Canonical field name
+	var canonical CreateCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"code":"CUST001","name":"John Doe","phone":"+254700123456"}`), &canonical))
+	assert.Equal(t, "+254700123456", canonical.Phone)
+
+	// Legacy field name from the old spec still binds
+	var legacy CreateCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"code":"CUST001","name":"John Doe","phone_number":"+254700654321"}`), &legacy))
+	assert.Equal(t, "+254700654321", legacy.Phone)
+
+	// The canonical field wins when both are present
+	var both CreateCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"phone":"+254700111111","phone_number":"+254700222222"}`), &both))
+	assert.Equal(t, "+254700111111", both.Phone)
+}
+
+func TestMoneySerialization(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Money
+		want   string
+	}{
+		{"two decimals preserved", 99.99, "99.99"},
+		{"whole number padded", 100, "100.00"},
+		{"float artifact rounded", Money(0.1 + 0.2), "0.30"},
+		{"half cent rounds", 10.005, "10.01"},
+		{"zero", 0, "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.amount)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+
+	// Round-trip through an order payload
+	order := Order{Amount: Money(999.99)}
+	data, err := json.Marshal(order)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"amount":999.99`)
+
+	var decoded Order
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, Money(999.99), decoded.Amount)
+}
+
+func TestTimestampsSerializeAsUTC(t *testing.T) {
+	nairobi, err := time.LoadLocation("Africa/Nairobi")
+	require.NoError(t, err)
+
+	createdAt := time.Date(2026, 3, 1, 15, 30, 0, 0, nairobi)
+
+	customer := Customer{ID: uuid.New(), Code: "CUST001", Name: "John", CreatedAt: createdAt, UpdatedAt: createdAt}
+	data, err := json.Marshal(customer)
+	require.NoError(t, err)
+
+	// Nairobi is UTC+3, so 15:30 EAT serializes as 12:30Z
+	assert.Contains(t, string(data), `"created_at":"2026-03-01T12:30:00Z"`)
+	assert.NotContains(t, string(data), "+03:00")
+
+	smsSentAt := createdAt.Add(time.Minute)
+	order := Order{ID: uuid.New(), OrderedAt: createdAt, CreatedAt: createdAt, UpdatedAt: createdAt, SMSSentAt: &smsSentAt}
+	data, err = json.Marshal(order)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"ordered_at":"2026-03-01T12:30:00Z"`)
+	assert.Contains(t, string(data), `"sms_sent_at":"2026-03-01T12:31:00Z"`)
+	assert.NotContains(t, string(data), "+03:00")
+}
+
+func TestUpdateCustomerRequest_PhoneNumberAlias(t *testing.T) {
+	var legacy UpdateCustomerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"phone_number":"+254700654321"}`), &legacy))
+	assert.Equal(t, "+254700654321", legacy.Phone)
+}
+
+func TestOrderListItemOmitsCustomerPII(t *testing.T) {
+	item := OrderListItem{
+		ID:           uuid.New(),
+		CustomerID:   uuid.New(),
+		CustomerName: "John Doe",
+		Item:         "Laptop",
+		Amount:       99.99,
+		Status:       OrderStatusPending,
+	}
+
+	data, err := json.Marshal(item)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `"customer_name":"John Doe"`)
+	assert.NotContains(t, string(data), "phone")
+	assert.NotContains(t, string(data), "email")
+}
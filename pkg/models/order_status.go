@@ -0,0 +1,62 @@
+package models
+
+import "fmt"
+
+// OrderStatus represents the lifecycle state of an order
+type OrderStatus string
+
+const (
+	OrderStatusPending     OrderStatus = "pending"
+	OrderStatusNeedsReview OrderStatus = "needs_review"
+	OrderStatusConfirmed   OrderStatus = "confirmed"
+	OrderStatusShipped     OrderStatus = "shipped"
+	OrderStatusDelivered   OrderStatus = "delivered"
+	OrderStatusCancelled   OrderStatus = "cancelled"
+)
+
+// orderStatusTransitions defines which status changes are allowed.
+// Terminal statuses (delivered, cancelled) have no outgoing transitions.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:     {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusNeedsReview: {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed:   {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:     {OrderStatusDelivered},
+	OrderStatusDelivered:   {},
+	OrderStatusCancelled:   {},
+}
+
+// Valid reports whether s is a known order status
+func (s OrderStatus) Valid() bool {
+	_, ok := orderStatusTransitions[s]
+	return ok
+}
+
+// String returns the status as a plain string
+func (s OrderStatus) String() string {
+	return string(s)
+}
+
+// CanTransitionTo reports whether the transition from s to next is allowed
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateOrderStatusTransition returns an error describing why a status
+// change is rejected, or nil if the transition is allowed.
+func ValidateOrderStatusTransition(from, to OrderStatus) error {
+	if !from.Valid() {
+		return fmt.Errorf("invalid order status: %q", from)
+	}
+	if !to.Valid() {
+		return fmt.Errorf("invalid order status: %q", to)
+	}
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("invalid status transition: %q -> %q", from, to)
+	}
+	return nil
+}
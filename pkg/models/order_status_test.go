@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatusValid(t *testing.T) {
+	for _, status := range []OrderStatus{
+		OrderStatusPending,
+		OrderStatusConfirmed,
+		OrderStatusShipped,
+		OrderStatusDelivered,
+		OrderStatusCancelled,
+	} {
+		assert.True(t, status.Valid(), "expected %q to be valid", status)
+	}
+
+	assert.False(t, OrderStatus("").Valid())
+	assert.False(t, OrderStatus("unknown").Valid())
+	assert.False(t, OrderStatus("PENDING").Valid())
+}
+
+func TestValidateOrderStatusTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    OrderStatus
+		to      OrderStatus
+		allowed bool
+	}{
+		{"pending to confirmed", OrderStatusPending, OrderStatusConfirmed, true},
+		{"pending to cancelled", OrderStatusPending, OrderStatusCancelled, true},
+		{"confirmed to shipped", OrderStatusConfirmed, OrderStatusShipped, true},
+		{"confirmed to cancelled", OrderStatusConfirmed, OrderStatusCancelled, true},
+		{"shipped to delivered", OrderStatusShipped, OrderStatusDelivered, true},
+
+		{"pending to shipped skips confirmation", OrderStatusPending, OrderStatusShipped, false},
+		{"pending to delivered skips fulfilment", OrderStatusPending, OrderStatusDelivered, false},
+		{"confirmed back to pending", OrderStatusConfirmed, OrderStatusPending, false},
+		{"delivered is terminal", OrderStatusDelivered, OrderStatusCancelled, false},
+		{"cancelled is terminal", OrderStatusCancelled, OrderStatusPending, false},
+		{"shipped to cancelled", OrderStatusShipped, OrderStatusCancelled, false},
+		{"unknown source status", OrderStatus("unknown"), OrderStatusConfirmed, false},
+		{"unknown target status", OrderStatusPending, OrderStatus("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOrderStatusTransition(tt.from, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+				assert.True(t, tt.from.CanTransitionTo(tt.to))
+			} else {
+				assert.Error(t, err)
+				assert.False(t, tt.from.CanTransitionTo(tt.to))
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageParamsNormalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         PageParams
+		wantLimit  int
+		wantOffset int
+	}{
+		{"defaults applied", PageParams{}, DefaultPageLimit, 0},
+		{"explicit values preserved", PageParams{Limit: 50, Offset: 100}, 50, 100},
+		{"limit clamped to max", PageParams{Limit: 500}, MaxPageLimit, 0},
+		{"negative limit defaulted", PageParams{Limit: -1}, DefaultPageLimit, 0},
+		{"negative offset zeroed", PageParams{Limit: 10, Offset: -5}, 10, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.Normalize()
+			assert.Equal(t, tt.wantLimit, tt.in.Limit)
+			assert.Equal(t, tt.wantOffset, tt.in.Offset)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Hand-written protobuf encoding for the list endpoints, wire-compatible
+// with the messages in savannah.proto. We encode with protowire directly
+// rather than generated code so the build doesn't depend on protoc.
+
+// appendString appends a string field if non-empty
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// appendInt64 appends a varint field if non-zero
+func appendInt64(b []byte, num protowire.Number, value int64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(value))
+}
+
+// appendBool appends a bool field if true
+func appendBool(b []byte, num protowire.Number, value bool) []byte {
+	if !value {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, 1)
+}
+
+// appendDouble appends a double field if non-zero
+func appendDouble(b []byte, num protowire.Number, value float64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(value))
+}
+
+// appendMessage appends an embedded message field
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func timeToMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// MarshalProto encodes the customer as a savannah.v1.Customer message
+func (c *Customer) MarshalProto() []byte {
+	var b []byte
+	b = appendString(b, 1, c.ID.String())
+	b = appendString(b, 2, c.Code)
+	b = appendString(b, 3, c.Name)
+	b = appendString(b, 4, c.Phone)
+	b = appendString(b, 5, c.Email)
+	b = appendString(b, 6, c.Locale)
+	b = appendInt64(b, 7, int64(c.Version))
+	b = appendBool(b, 8, c.IsActive)
+	b = appendInt64(b, 9, timeToMillis(c.CreatedAt))
+	b = appendInt64(b, 10, timeToMillis(c.UpdatedAt))
+	return b
+}
+
+// MarshalProto encodes the order as a savannah.v1.Order message
+func (o *Order) MarshalProto() []byte {
+	var b []byte
+	b = appendString(b, 1, o.ID.String())
+	b = appendString(b, 2, o.CustomerID.String())
+	b = appendString(b, 3, o.Item)
+	b = appendDouble(b, 4, float64(o.Amount))
+	b = appendInt64(b, 5, timeToMillis(o.OrderedAt))
+	b = appendString(b, 6, string(o.Status))
+	if o.SMSSentAt != nil {
+		b = appendInt64(b, 7, timeToMillis(*o.SMSSentAt))
+	}
+	b = appendInt64(b, 8, int64(o.Version))
+	b = appendBool(b, 9, o.IsActive)
+	b = appendInt64(b, 10, timeToMillis(o.CreatedAt))
+	b = appendInt64(b, 11, timeToMillis(o.UpdatedAt))
+	if o.Customer.ID != uuid.Nil {
+		b = appendMessage(b, 12, o.Customer.MarshalProto())
+	}
+	return b
+}
+
+// MarshalCustomerListProto encodes a savannah.v1.CustomerList message
+func MarshalCustomerListProto(customers []Customer, total int64) []byte {
+	var b []byte
+	for i := range customers {
+		b = appendMessage(b, 1, customers[i].MarshalProto())
+	}
+	b = appendInt64(b, 2, total)
+	return b
+}
+
+// MarshalProto encodes the list item as a savannah.v1.Order message (the
+// nested customer message is omitted; customer_name carries field 13)
+func (o *OrderListItem) MarshalProto() []byte {
+	var b []byte
+	b = appendString(b, 1, o.ID.String())
+	b = appendString(b, 2, o.CustomerID.String())
+	b = appendString(b, 3, o.Item)
+	b = appendDouble(b, 4, float64(o.Amount))
+	b = appendInt64(b, 5, timeToMillis(o.OrderedAt))
+	b = appendString(b, 6, string(o.Status))
+	if o.SMSSentAt != nil {
+		b = appendInt64(b, 7, timeToMillis(*o.SMSSentAt))
+	}
+	b = appendInt64(b, 10, timeToMillis(o.CreatedAt))
+	b = appendString(b, 13, o.CustomerName)
+	return b
+}
+
+// MarshalOrderListProto encodes a savannah.v1.OrderList message
+func MarshalOrderListProto(orders []OrderListItem, total int64) []byte {
+	var b []byte
+	for i := range orders {
+		b = appendMessage(b, 1, orders[i].MarshalProto())
+	}
+	b = appendInt64(b, 2, total)
+	return b
+}
+
+// UnmarshalProto decodes a savannah.v1.Customer message
+func (c *Customer) UnmarshalProto(b []byte) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch typ {
+		case protowire.BytesType:
+			value, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+
+			switch num {
+			case 1:
+				id, err := uuid.Parse(value)
+				if err != nil {
+					return fmt.Errorf("invalid customer id: %w", err)
+				}
+				c.ID = id
+			case 2:
+				c.Code = value
+			case 3:
+				c.Name = value
+			case 4:
+				c.Phone = value
+			case 5:
+				c.Email = value
+			case 6:
+				c.Locale = value
+			}
+
+		case protowire.VarintType:
+			value, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+
+			switch num {
+			case 7:
+				c.Version = int(value)
+			case 8:
+				c.IsActive = value != 0
+			case 9:
+				c.CreatedAt = time.UnixMilli(int64(value)).UTC()
+			case 10:
+				c.UpdatedAt = time.UnixMilli(int64(value)).UTC()
+			}
+
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+// UnmarshalCustomerListProto decodes a savannah.v1.CustomerList message
+func UnmarshalCustomerListProto(b []byte) ([]Customer, int64, error) {
+	var customers []Customer
+	var total int64
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			message, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, 0, protowire.ParseError(n)
+			}
+			b = b[n:]
+
+			var customer Customer
+			if err := customer.UnmarshalProto(message); err != nil {
+				return nil, 0, err
+			}
+			customers = append(customers, customer)
+
+		case num == 2 && typ == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, 0, protowire.ParseError(n)
+			}
+			b = b[n:]
+			total = int64(value)
+
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return nil, 0, protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+
+	return customers, total, nil
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleCustomers(n int) []Customer {
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	customers := make([]Customer, n)
+	for i := range customers {
+		customers[i] = Customer{
+			ID:        uuid.New(),
+			Code:      "CUST001",
+			Name:      "John Doe",
+			Phone:     "+254700123456",
+			Email:     "john@example.com",
+			Locale:    "en",
+			Version:   2,
+			IsActive:  true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	return customers
+}
+
+func TestCustomerListProtoRoundTrip(t *testing.T) {
+	customers := sampleCustomers(3)
+
+	encoded := MarshalCustomerListProto(customers, 42)
+	decoded, total, err := UnmarshalCustomerListProto(encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(42), total)
+	require.Len(t, decoded, 3)
+
+	for i := range customers {
+		assert.Equal(t, customers[i].ID, decoded[i].ID)
+		assert.Equal(t, customers[i].Code, decoded[i].Code)
+		assert.Equal(t, customers[i].Name, decoded[i].Name)
+		assert.Equal(t, customers[i].Phone, decoded[i].Phone)
+		assert.Equal(t, customers[i].Email, decoded[i].Email)
+		assert.Equal(t, customers[i].Locale, decoded[i].Locale)
+		assert.Equal(t, customers[i].Version, decoded[i].Version)
+		assert.Equal(t, customers[i].IsActive, decoded[i].IsActive)
+		assert.True(t, customers[i].CreatedAt.Equal(decoded[i].CreatedAt))
+	}
+}
+
+func TestOrderListProtoEncodesWithoutError(t *testing.T) {
+	now := time.Now()
+	orders := []OrderListItem{
+		{
+			ID:           uuid.New(),
+			CustomerID:   uuid.New(),
+			CustomerName: "John Doe",
+			Item:         "Laptop",
+			Amount:       999.99,
+			OrderedAt:    now,
+			Status:       OrderStatusPending,
+			SMSSentAt:    &now,
+			CreatedAt:    now,
+		},
+	}
+
+	encoded := MarshalOrderListProto(orders, 1)
+	assert.NotEmpty(t, encoded)
+
+	// Protobuf should be noticeably smaller than the JSON equivalent
+	jsonEncoded, err := json.Marshal(orders)
+	require.NoError(t, err)
+	assert.Less(t, len(encoded), len(jsonEncoded))
+}
+
+func BenchmarkCustomerListJSON(b *testing.B) {
+	customers := sampleCustomers(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(customers); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCustomerListProto(b *testing.B) {
+	customers := sampleCustomers(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MarshalCustomerListProto(customers, int64(len(customers)))
+	}
+}
@@ -1,88 +1,197 @@
 package routes
 
 import (
+	"net/http"
+	"net/http/pprof"
+	"time"
+
 	"backend/internal/api/v1"
 	"backend/internal/auth"
+	"backend/internal/docs"
+	"backend/internal/jobs"
+	"backend/internal/monitoring"
+	"backend/internal/ratelimit"
 	"backend/internal/repositories"
 	"backend/internal/services"
+	"backend/pkg/config"
+	"backend/pkg/database"
+	"backend/pkg/middleware"
+	"backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// SetupMonitoringRoutes registers the health and metrics endpoints
+// documented in the OpenAPI spec
+func SetupMonitoringRoutes(router *gin.Engine, healthChecker *monitoring.HealthChecker, metrics *monitoring.Metrics, metricsGuard gin.HandlerFunc) {
+	router.GET("/health", healthChecker.HealthHandler())
+	router.GET("/health/ready", healthChecker.ReadinessHandler())
+	router.GET("/health/live", healthChecker.LivenessHandler())
+	router.GET("/health/history", healthChecker.HistoryHandler())
+	router.GET("/metrics", metricsGuard, gin.WrapH(metrics.Handler()))
+}
+
+// SetupDebugRoutes mounts net/http/pprof under /debug/pprof when enabled
+// (default off), behind the given guard so profiles can't be pulled by
+// anonymous callers. Off means the routes simply don't exist.
+func SetupDebugRoutes(router *gin.Engine, enabled bool, guard gin.HandlerFunc) {
+	if !enabled {
+		return
+	}
+
+	debug := router.Group("/debug/pprof", guard)
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, profile := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		debug.GET("/"+profile, gin.WrapH(pprof.Handler(profile)))
+	}
+}
+
+// SetupNoRoute registers a JSON 404 for unmatched paths so clients always
+// receive the standard response envelope instead of Gin's plain-text 404
+func SetupNoRoute(router *gin.Engine) {
+	router.NoRoute(func(c *gin.Context) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Route not found", map[string]string{"code": "not_found"})
+	})
+}
+
 // SetupRoutes configures all the routes for the application
-func SetupRoutes(router *gin.Engine, db *gorm.DB, oidcProvider *auth.OIDCProvider, smsService *services.SMSService) {
+func SetupRoutes(router *gin.Engine, db *gorm.DB, authn auth.Authenticator, smsService *services.SMSService, jobQueue *jobs.RedisJobQueue, cfg *config.Config, logger *monitoring.Logger, metricsRecorder *monitoring.Metrics) {
 	// Initialize repositories
 	customerRepo := repositories.NewCustomerRepository(db)
 	orderRepo := repositories.NewOrderRepository(db)
+	shortLinkRepo := repositories.NewShortLinkRepository(db)
+	smsJobRepo := repositories.NewSMSJobRepository(db)
+	orderEventRepo := repositories.NewOrderEventRepository(db)
+
+	// Initialize services
+	smsService.SetCustomerStore(customerRepo)
+	smsService.SetShortLinkStore(shortLinkRepo)
+	smsService.SetJobRecorder(smsJobRepo)
+	smsService.SetOrderStore(orderRepo)
+	smsService.SetCostTracker(jobQueue)
+	smsService.SetDeliveryHooks(jobQueue, metricsRecorder, smsJobRepo)
+	smsService.SetFeatureFlags(cfg.Features)
+	customerService := services.NewCustomerService(customerRepo)
+	customerService.SetCodeGeneration(cfg.Customers.AutoGenerateCodes, cfg.Customers.CodePrefix)
+	customerService.SetFeatureFlags(cfg.Features)
+	// Export reads go to the replica when one is configured
+	if readDB := database.GetReadDB(); readDB != nil && readDB != db {
+		customerService.SetExportStore(repositories.NewCustomerRepository(readDB))
+	}
+	orderService := services.NewOrderService(orderRepo, customerRepo, smsService)
+	orderService.SetMaxAutoAmount(cfg.Orders.MaxAutoAmount)
+	orderService.SetAutoConfirm(cfg.Orders.AutoConfirm)
+	orderService.SetEventStore(orderEventRepo)
+	if cfg.Orders.DedupWindowSeconds > 0 {
+		orderService.SetDuplicateGuard(ratelimit.NewSubmissionDedup(
+			jobQueue.Client(),
+			time.Duration(cfg.Orders.DedupWindowSeconds)*time.Second,
+		))
+	}
+	if cfg.Orders.MaxPerWindow > 0 {
+		orderService.SetRateLimiter(ratelimit.NewPerCustomerOrders(
+			jobQueue.Client(),
+			cfg.Orders.MaxPerWindow,
+			time.Duration(cfg.Orders.WindowSeconds)*time.Second,
+		))
+	}
 
 	// Initialize handlers
-	customerHandler := v1.NewCustomerHandler(customerRepo)
-	orderHandler := v1.NewOrderHandler(orderRepo, customerRepo, smsService)
+	customerHandler := v1.NewCustomerHandler(customerService)
+	orderHandler := v1.NewOrderHandler(orderService, smsService)
+	adminHandler := v1.NewAdminHandler(jobQueue, smsJobRepo, smsService, logger, cfg)
+	shortLinkHandler := v1.NewShortLinkHandler(shortLinkRepo)
+
+	// Short links from order SMS resolve outside the versioned API
+	router.GET("/o/:code", shortLinkHandler.Resolve)
+
+	// Provider callbacks are form-encoded, so they live outside the
+	// JSON-only API group
+	webhookHandler := v1.NewWebhookHandler(smsService, cfg.SMS.WebhookToken)
+	router.POST("/webhooks/sms/delivery", webhookHandler.SMSDeliveryReport)
+
+	// Shed order-creation load when the SMS backlog is saturated
+	backpressure := middleware.QueueBackpressure(int64(cfg.SMS.QueueHighWater), 30*time.Second, jobQueue.PendingDepth)
 
 	// API v1 routes
 	api := router.Group("/api/v1")
+	api.Use(middleware.RequireJSON())
+	api.Use(middleware.StrictJSON(cfg.StrictJSONDecoding))
 	{
+		// Permission discovery for frontends (registered routes populate
+		// the scope/role sets below, so keep this unauthenticated route
+		// handler reading them lazily)
+		api.GET("/auth/permissions", authn.PermissionsHandler())
+
+		// Generated JSON Schemas for the request/response models
+		api.GET("/schemas", docs.SchemasHandler())
+
 		// Customer routes
 		customers := api.Group("/customers")
 		{
 			// Public routes (with basic auth)
-			customers.POST("/", oidcProvider.RequireScopes("customers:write"), customerHandler.CreateCustomer)
-			customers.GET("/", oidcProvider.RequireScopes("customers:read"), customerHandler.ListCustomers)
-			customers.GET("/:id", oidcProvider.RequireScopes("customers:read"), customerHandler.GetCustomer)
-			customers.PUT("/:id", oidcProvider.RequireScopes("customers:write"), customerHandler.UpdateCustomer)
-			customers.DELETE("/:id", oidcProvider.RequireRoles("admin"), customerHandler.DeleteCustomer)
-			
+			customers.POST("/", authn.RequireScopes("customers:write"), customerHandler.CreateCustomer)
+			customers.GET("/", authn.RequireScopes("customers:read"), customerHandler.ListCustomers)
+			customers.GET("/export", authn.RequireScopes("customers:read"), customerHandler.ExportCustomers)
+			customers.GET("/:id", authn.RequireScopes("customers:read"), customerHandler.GetCustomer)
+			customers.HEAD("/:id", authn.RequireScopes("customers:read"), customerHandler.HeadCustomer)
+			customers.PUT("/:id", authn.RequireScopes("customers:write"), customerHandler.UpdateCustomer)
+			customers.PATCH("/:id", authn.RequireScopes("customers:write"), customerHandler.PatchCustomer)
+			customers.DELETE("/:id", authn.RequireRoles("admin"), customerHandler.DeleteCustomer)
+
 			// Customer orders
-			customers.GET("/:id/orders", oidcProvider.RequireScopes("orders:read"), orderHandler.GetCustomerOrders)
+			customers.GET("/:id/orders", authn.RequireScopes("orders:read"), orderHandler.GetCustomerOrders)
 		}
 
 		// Order routes
 		orders := api.Group("/orders")
 		{
-			orders.POST("/", oidcProvider.RequireScopes("orders:write"), orderHandler.CreateOrder)
-			orders.GET("/", oidcProvider.RequireScopes("orders:read"), orderHandler.ListOrders)
-			orders.GET("/:id", oidcProvider.RequireScopes("orders:read"), orderHandler.GetOrder)
+			orders.POST("/", authn.RequireScopes("orders:write"), backpressure, orderHandler.CreateOrder)
+			orders.GET("/", authn.RequireScopes("orders:read"), orderHandler.ListOrders)
+			orders.GET("/recent", authn.RequireScopes("orders:read"), orderHandler.GetRecentOrders)
+			orders.GET("/:id", authn.RequireScopes("orders:read"), orderHandler.GetOrder)
+			orders.HEAD("/:id", authn.RequireScopes("orders:read"), orderHandler.HeadOrder)
+			orders.GET("/:id/sms-preview", authn.RequireScopes("orders:read"), orderHandler.GetOrderSMSPreview)
+			orders.GET("/:id/events", authn.RequireScopes("orders:read"), orderHandler.GetOrderEvents)
+			orders.PATCH("/:id", authn.RequireScopes("orders:write"), orderHandler.PatchOrder)
+			orders.POST("/:id/cancel", authn.RequireScopes("orders:write"), orderHandler.CancelOrder)
 		}
 
 		// Admin routes
 		admin := api.Group("/admin")
-		admin.Use(oidcProvider.RequireRoles("admin"))
+		admin.Use(authn.RequireRoles("admin"))
 		{
+			admin.GET("/config", adminHandler.GetConfig)
+			admin.POST("/log-level", adminHandler.SetLogLevel)
+			admin.GET("/orders/review", orderHandler.ListOrdersNeedingReview)
+			admin.POST("/orders/backfill-sms", orderHandler.BackfillSMS)
+			admin.GET("/sms/history", adminHandler.ListSMSHistory)
+			admin.GET("/sms/jobs", adminHandler.ListSMSJobs)
+			admin.GET("/sms/jobs/:id", adminHandler.GetSMSJob)
+			admin.GET("/sms/stream", adminHandler.StreamSMSStats)
+			admin.POST("/sms/cleanup", adminHandler.CleanupSMSJobs)
+			admin.POST("/sms/test", adminHandler.TestSMS)
+			admin.POST("/sms/retry-failed", adminHandler.RetryFailedSMSJobs)
 			admin.GET("/stats", func(c *gin.Context) {
-				// Get SMS job stats
+				// Get SMS job stats and today's spend
 				smsStats, _ := smsService.GetSMSJobStats(c.Request.Context())
-				
+				spend, _ := smsService.TodaySpend(c.Request.Context())
+
 				c.JSON(200, gin.H{
 					"message": "Admin statistics",
 					"stats": gin.H{
-						"sms_jobs": smsStats,
+						"sms_jobs":        smsStats,
+						"sms_spend_today": spend,
 					},
 				})
 			})
 		}
 	}
 
-	// API documentation route
-	router.GET("/docs", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "Savannah Backend API Documentation",
-			"version": "1.0.0",
-			"endpoints": gin.H{
-				"health":            "GET /health",
-				"customers":         "GET|POST /api/v1/customers (auth: customers:read|write)",
-				"customer_by_id":    "GET|PUT|DELETE /api/v1/customers/:id",
-				"customer_orders":   "GET /api/v1/customers/:id/orders",
-				"orders":            "GET|POST /api/v1/orders (auth: orders:read|write)",
-				"order_by_id":       "GET /api/v1/orders/:id",
-				"admin_stats":       "GET /api/v1/admin/stats (role: admin)",
-			},
-			"authentication": gin.H{
-				"type":   "OIDC Bearer Token",
-				"header": "Authorization: Bearer <access_token>",
-				"scopes": []string{"customers:read", "customers:write", "orders:read", "orders:write"},
-				"roles":  []string{"admin"},
-			},
-		})
-	})
 }
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// SnakeToCamel converts a snake_case key to camelCase
+func SnakeToCamel(key string) string {
+	parts := strings.Split(key, "_")
+	if len(parts) == 1 {
+		return key
+	}
+
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+// convertKeys re-keys every object in a decoded JSON document
+func convertKeys(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for key, nested := range typed {
+			converted[SnakeToCamel(key)] = convertKeys(nested)
+		}
+		return converted
+	case []interface{}:
+		for i, nested := range typed {
+			typed[i] = convertKeys(nested)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// ConvertKeysToCamel re-keys a JSON document's snake_case object keys to
+// camelCase without touching the values, so partner consumers can get
+// camelCase responses while the models keep their snake_case tags.
+// Numbers are decoded as json.Number so their exact literals survive the
+// round-trip (a float64 detour would turn Money's "100.00" into "100").
+func ConvertKeysToCamel(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(convertKeys(doc))
+}
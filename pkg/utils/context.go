@@ -0,0 +1,51 @@
+package utils
+
+import "context"
+
+// Distinct unexported key types so request-scoped values can't collide
+// with other packages' context usage (and go vet stops flagging bare
+// string keys)
+type (
+	correlationIDKey struct{}
+	requestIDKey     struct{}
+	userIDKey        struct{}
+)
+
+// WithCorrelationID returns a context carrying the request's correlation ID
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID, or ""
+func CorrelationIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(correlationIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// WithRequestID returns a context carrying the server-assigned request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// WithUserID returns a context carrying the authenticated user's subject
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserIDFromContext returns the authenticated user ID, or ""
+func UserIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(userIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// EncodeCursor wraps a keyset position (the last row's ID) in an opaque
+// resumable token
+func EncodeCursor(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id.String()))
+}
+
+// DecodeCursor unwraps a token produced by EncodeCursor. An empty cursor
+// decodes to the zero UUID, meaning "start from the beginning".
+func DecodeCursor(cursor string) (uuid.UUID, error) {
+	if cursor == "" {
+		return uuid.Nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	id, err := uuid.Parse(string(raw))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return id, nil
+}
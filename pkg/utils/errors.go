@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// pgUniqueViolationCode is the Postgres error code for unique constraint violations
+const pgUniqueViolationCode = "23505"
+
+// StrictJSONKey is the gin-context flag (set by middleware from config)
+// that makes BindJSON reject unknown request fields
+const StrictJSONKey = "strict_json"
+
+// extraJSONFields lets request types with custom unmarshalers extend the
+// strict-mode allowlist with the alias keys their UnmarshalJSON accepts
+// (e.g. the customer requests' legacy phone_number)
+type extraJSONFields interface {
+	ExtraJSONFields() []string
+}
+
+// knownJSONFields collects a struct's top-level json key names, plus any
+// aliases it declares via ExtraJSONFields. Returns nil for non-structs.
+func knownJSONFields(obj interface{}) map[string]bool {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if name == "-" {
+			continue
+		}
+		fields[name] = true
+	}
+
+	if extra, ok := obj.(extraJSONFields); ok {
+		for _, name := range extra.ExtraJSONFields() {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// checkUnknownFields rejects top-level keys the target type doesn't
+// declare. DisallowUnknownFields can't see inside a custom UnmarshalJSON,
+// so this covers types like CreateCustomerRequest that decode themselves.
+func checkUnknownFields(body []byte, obj interface{}) error {
+	known := knownJSONFields(obj)
+	if known == nil {
+		return nil
+	}
+
+	var topLevel map[string]json.RawMessage
+	if err := json.Unmarshal(body, &topLevel); err != nil {
+		// Non-object bodies are left to the real decoder to report
+		return nil
+	}
+
+	var unknown []string
+	for key := range topLevel {
+		if !known[key] {
+			unknown = append(unknown, fmt.Sprintf("%q", key))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return fmt.Errorf("json: unknown field %s", strings.Join(unknown, ", "))
+}
+
+// BindJSON decodes the request body into obj, normalizes its string
+// fields (trimming, name whitespace collapsing) and only then validates,
+// so length rules apply to what will actually be stored. With strict
+// decoding enabled for the request, unknown fields are rejected with an
+// error that names them, so typos like "phon" surface instead of being
+// silently dropped — including on types with custom UnmarshalJSON, which
+// checkUnknownFields covers since DisallowUnknownFields can't.
+func BindJSON(c *gin.Context, obj interface{}) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if c.GetBool(StrictJSONKey) {
+		decoder.DisallowUnknownFields()
+		if err := checkUnknownFields(body, obj); err != nil {
+			return err
+		}
+	}
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+
+	NormalizeStrings(obj)
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// RequireBody rejects empty or whitespace-only request bodies with a
+// clear error code, instead of the confusing EOF the JSON binder would
+// produce. Returns false after writing the response.
+func RequireBody(c *gin.Context) bool {
+	if c.Request.Body != nil {
+		body, err := io.ReadAll(c.Request.Body)
+		if err == nil && len(bytes.TrimSpace(body)) > 0 {
+			// Hand the bytes back so binding still works
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			return true
+		}
+	}
+
+	ErrorResponse(c, http.StatusBadRequest, "Request body is required", map[string]string{
+		"code": "request.empty_body",
+	})
+	return false
+}
+
+// fieldErrors flattens validator errors into per-field rule details
+func fieldErrors(err error) map[string]string {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make(map[string]string, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			details[strings.ToLower(fieldErr.Field())] = fmt.Sprintf("failed on the '%s' rule", fieldErr.Tag())
+		}
+		return details
+	}
+	return map[string]string{"error": err.Error()}
+}
+
+// RespondQueryValidationError writes the structured 400 for invalid query
+// parameters, matching the field-error shape used for body validation
+func RespondQueryValidationError(c *gin.Context, err error) {
+	ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", gin.H{
+		"code":   "validation.query",
+		"fields": fieldErrors(err),
+	})
+}
+
+// RespondError inspects err and writes the matching HTTP status and error
+// code in the standard response envelope, so handlers don't each hand-roll
+// the gorm.ErrRecordNotFound dance or leak raw database errors as 500s.
+func RespondError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, models.ErrCustomerNotFound),
+		errors.Is(err, models.ErrOrderNotFound):
+		ErrorResponse(c, http.StatusNotFound, "Resource not found", map[string]string{"code": "not_found"})
+
+	case errors.Is(err, models.ErrVersionConflict):
+		ErrorResponse(c, http.StatusConflict, "Resource was modified concurrently", map[string]string{"code": "conflict.version"})
+
+	case errors.Is(err, gorm.ErrDuplicatedKey),
+		errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolationCode:
+		ErrorResponse(c, http.StatusConflict, "Resource already exists", map[string]string{"code": "conflict.unique_violation"})
+
+	case errors.Is(err, models.ErrImmutableField):
+		ErrorResponse(c, http.StatusBadRequest, "Field cannot be changed after creation", gin.H{
+			"code":   "field.immutable",
+			"detail": err.Error(),
+		})
+
+	case errors.As(err, &validationErrs), errors.Is(err, models.ErrValidation):
+		ErrorResponse(c, http.StatusBadRequest, "Validation failed", gin.H{
+			"code":   "validation",
+			"detail": err.Error(),
+		})
+
+	case errors.Is(err, models.ErrPossibleDuplicate):
+		ErrorResponse(c, http.StatusConflict, "An identical order was just submitted; pass force=true to override", map[string]string{
+			"code": "order.possible_duplicate",
+		})
+
+	case errors.Is(err, models.ErrOrderRateLimited):
+		ErrorResponse(c, http.StatusTooManyRequests, "Too many orders for this customer, try again later", map[string]string{
+			"code": "order.rate_limited",
+		})
+
+	case errors.Is(err, context.DeadlineExceeded):
+		ErrorResponse(c, http.StatusGatewayTimeout, "Request timed out", map[string]string{"code": "timeout"})
+
+	case errors.Is(err, context.Canceled):
+		// 499: client closed the request (nginx convention); there is no
+		// stdlib constant for it
+		ErrorResponse(c, 499, "Request cancelled by client", map[string]string{"code": "cancelled"})
+
+	default:
+		// Never leak raw internal error text to clients
+		ErrorResponse(c, http.StatusInternalServerError, "Internal server error", map[string]string{"code": "internal_error"})
+	}
+}
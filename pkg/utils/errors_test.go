@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"backend/pkg/models"
+)
+
+func respondErrorStatus(t *testing.T, err error) (int, models.Response) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	RespondError(c, err)
+
+	var body models.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func errorCode(t *testing.T, body models.Response) string {
+	t.Helper()
+	payload, ok := body.Error.(map[string]interface{})
+	require.True(t, ok, "error payload should be an object, got %T", body.Error)
+	code, _ := payload["code"].(string)
+	return code
+}
+
+func TestRespondError_Mapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"record not found", gorm.ErrRecordNotFound, http.StatusNotFound, "not_found"},
+		{"wrapped record not found", errors.Join(errors.New("lookup failed"), gorm.ErrRecordNotFound), http.StatusNotFound, "not_found"},
+		{"version conflict", models.ErrVersionConflict, http.StatusConflict, "conflict.version"},
+		{"gorm duplicated key", gorm.ErrDuplicatedKey, http.StatusConflict, "conflict.unique_violation"},
+		{"postgres unique violation", &pgconn.PgError{Code: "23505"}, http.StatusConflict, "conflict.unique_violation"},
+		{"immutable field", models.ErrImmutableField, http.StatusBadRequest, "field.immutable"},
+		{"context deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout"},
+		{"context cancelled", context.Canceled, 499, "cancelled"},
+		{"wrapped cancelled from gorm", fmt.Errorf("list failed: %w", context.Canceled), 499, "cancelled"},
+		{"unknown error", errors.New("pq: something exploded"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := respondErrorStatus(t, tt.err)
+			assert.Equal(t, tt.status, status)
+			assert.False(t, body.Success)
+			assert.Equal(t, tt.code, errorCode(t, body))
+		})
+	}
+}
+
+func TestSnakeToCamel(t *testing.T) {
+	assert.Equal(t, "customerId", SnakeToCamel("customer_id"))
+	assert.Equal(t, "smsSentAt", SnakeToCamel("sms_sent_at"))
+	assert.Equal(t, "name", SnakeToCamel("name"))
+	assert.Equal(t, "id", SnakeToCamel("id"))
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	cursor := EncodeCursor(id)
+
+	decoded, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, id, decoded)
+
+	// Empty cursor means "start from the beginning"
+	decoded, err = DecodeCursor("")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, decoded)
+
+	_, err = DecodeCursor("!!!not-base64!!!")
+	assert.Error(t, err)
+}
+
+func TestRespondError_DoesNotLeakInternalDetails(t *testing.T) {
+	status, body := respondErrorStatus(t, errors.New("password=hunter2 connection refused"))
+
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.NotContains(t, body.Message, "hunter2")
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "hunter2")
+}
+
+func TestContextIDRoundTrips(t *testing.T) {
+	ctx := context.Background()
+
+	ctx = WithCorrelationID(ctx, "corr-1")
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithUserID(ctx, "user-1")
+
+	assert.Equal(t, "corr-1", CorrelationIDFromContext(ctx))
+	assert.Equal(t, "req-1", RequestIDFromContext(ctx))
+	assert.Equal(t, "user-1", UserIDFromContext(ctx))
+
+	// Values are absent rather than colliding on a bare string key
+	empty := context.Background()
+	assert.Empty(t, CorrelationIDFromContext(empty))
+	assert.Empty(t, RequestIDFromContext(empty))
+	assert.Empty(t, UserIDFromContext(empty))
+}
+
+func TestBindJSON_StrictRejectsUnknownFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type payload struct {
+		Name string `json:"name" binding:"required"`
+	}
+
+	newCtx := func(strict bool, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		if strict {
+			c.Set(StrictJSONKey, true)
+		}
+		return c
+	}
+
+	// Lenient mode silently ignores the typo
+	var lenient payload
+	require.NoError(t, BindJSON(newCtx(false, `{"name":"x","naem":"typo"}`), &lenient))
+
+	// Strict mode names the unknown field
+	var strict payload
+	err := BindJSON(newCtx(true, `{"name":"x","naem":"typo"}`), &strict)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "naem"`)
+
+	// Validation still applies in strict mode
+	var invalid payload
+	err = BindJSON(newCtx(true, `{}`), &invalid)
+	require.Error(t, err)
+}
+
+func TestNormalizeStrings(t *testing.T) {
+	type item struct {
+		Name string `normalize:"name"`
+		Note string
+	}
+	type request struct {
+		Name  string `normalize:"name"`
+		Email string
+		Alias *string `normalize:"name"`
+		Items []item
+	}
+
+	alias := "  Jane   Q  "
+	req := &request{
+		Name:  " John  Doe ",
+		Email: "  john@example.com  ",
+		Alias: &alias,
+		Items: []item{{Name: "  Big   Laptop ", Note: " keep  inner "}},
+	}
+
+	NormalizeStrings(req)
+
+	assert.Equal(t, "John Doe", req.Name)
+	assert.Equal(t, "john@example.com", req.Email)
+	assert.Equal(t, "Jane Q", *req.Alias)
+	assert.Equal(t, "Big Laptop", req.Items[0].Name)
+	// Plain strings are trimmed but keep their internal spacing
+	assert.Equal(t, "keep  inner", req.Items[0].Note)
+}
+
+func TestConvertKeysToCamel_PreservesNumberLiterals(t *testing.T) {
+	// Money renders with fixed two decimals; the re-keying must not
+	// round-trip numbers through float64 and strip them
+	converted, err := ConvertKeysToCamel([]byte(`{"order_amount":100.00,"big_id":9007199254740993}`))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(converted), `"orderAmount":100.00`)
+	assert.Contains(t, string(converted), `"bigId":9007199254740993`)
+}
+
+func TestBindJSON_StrictCoversAliasUnmarshalers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newCtx := func(body string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(StrictJSONKey, true)
+		return c
+	}
+
+	// DisallowUnknownFields can't see through CreateCustomerRequest's
+	// custom UnmarshalJSON, so the motivating typo must still be caught
+	var typo models.CreateCustomerRequest
+	err := BindJSON(newCtx(`{"code":"CUST001","name":"John Doe","phon":"+254700123456"}`), &typo)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "phon"`)
+
+	// The declared phone_number alias stays accepted in strict mode
+	var legacy models.CreateCustomerRequest
+	require.NoError(t, BindJSON(newCtx(`{"code":"CUST001","name":"John Doe","phone_number":"+254700123456"}`), &legacy))
+	assert.Equal(t, "+254700123456", legacy.Phone)
+
+	// The update request's alias works the same way
+	var update models.UpdateCustomerRequest
+	err = BindJSON(newCtx(`{"emial":"x@example.com"}`), &update)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "emial"`)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+)
+
+// collapseWhitespace trims and squeezes runs of whitespace to single spaces
+func collapseWhitespace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// NormalizeStrings walks a request struct (through pointers and slices)
+// and trims every string field; fields tagged `normalize:"name"` also have
+// internal whitespace collapsed, so " John  Doe " is stored as "John Doe".
+// Centralizing this here means every bound request benefits without
+// per-handler cleanup code.
+func NormalizeStrings(v interface{}) {
+	normalizeValue(reflect.ValueOf(v))
+}
+
+func normalizeValue(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			normalizeValue(value.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			normalizeValue(value.Index(i))
+		}
+	case reflect.Struct:
+		structType := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+
+			switch field.Kind() {
+			case reflect.String:
+				normalized := strings.TrimSpace(field.String())
+				if structType.Field(i).Tag.Get("normalize") == "name" {
+					normalized = collapseWhitespace(normalized)
+				}
+				field.SetString(normalized)
+			case reflect.Ptr:
+				if !field.IsNil() && field.Elem().Kind() == reflect.String {
+					normalized := strings.TrimSpace(field.Elem().String())
+					if structType.Field(i).Tag.Get("normalize") == "name" {
+						normalized = collapseWhitespace(normalized)
+					}
+					field.Elem().SetString(normalized)
+				} else {
+					normalizeValue(field)
+				}
+			case reflect.Struct, reflect.Slice:
+				normalizeValue(field)
+			}
+		}
+	}
+}
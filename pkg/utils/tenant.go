@@ -0,0 +1,21 @@
+package utils
+
+import "context"
+
+// tenantKey is an unexported context key type so tenant values can't
+// collide with other packages' context usage
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the caller's tenant ID
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the caller's tenant ID, or "" when the request
+// is not tenant-scoped
+func TenantFromContext(ctx context.Context) string {
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+		return tenant
+	}
+	return ""
+}